@@ -0,0 +1,156 @@
+package gom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// ErrNoActor is returned in strict actor mode when a write touches a
+// createdBy / updatedBy column and the context carries no actor.
+var ErrNoActor = errors.New("gom: no actor in context")
+
+type actorKey struct{}
+
+// WithActor returns a context whose writes fill the columns tagged
+// gom:"createdBy" / gom:"updatedBy" with actor, usually the user id.
+func WithActor(ctx context.Context, actor interface{}) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom reports the actor stored by WithActor.
+func ActorFrom(ctx context.Context) (interface{}, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	actor := ctx.Value(actorKey{})
+	return actor, actor != nil
+}
+
+// RequireActor makes every Insert, Save, Update, UpdateMap and Delete on a
+// table with createdBy / updatedBy columns fail with ErrNoActor when the
+// context has no actor, SkipHooks chains included. A table is known once
+// its struct was passed to RegisterModel, Model, Insert or Save; register
+// models at startup so Table chains are checked from the first call. Exec
+// and Raw are not checked.
+func (m *ConDB) RequireActor(strict bool) {
+	m.root().strictActor = strict
+}
+
+// actorTables 记录含操作人列的表：logical table -> updatedBy 列名
+var actorTables sync.Map
+
+func registerActorTable(table string, t reflect.Type) {
+	fields := actorFields(t)
+	if len(fields) == 0 {
+		return
+	}
+	var updated []string
+	for _, f := range updatedByOnly(fields) {
+		updated = append(updated, f.column)
+	}
+	sort.Strings(updated)
+	actorTables.Store(table, updated)
+}
+
+// requireActor 严格模式下写入含操作人列的表时必须有操作人
+func (m *ConDB) requireActor(table string) error {
+	if !m.root().strictActor {
+		return nil
+	}
+	if _, ok := actorTables.Load(table); !ok {
+		return nil
+	}
+	if _, ok := ActorFrom(m.context()); ok {
+		return nil
+	}
+	return fmt.Errorf("%w: writing %s", ErrNoActor, table)
+}
+
+// actorField 一个 createdBy / updatedBy 列
+type actorField struct {
+	column string
+	index  []int
+	typ    reflect.Type
+	create bool
+}
+
+func actorFields(t reflect.Type) []actorField {
+	var out []actorField
+	for _, idx := range getFieldMap(t) {
+		col := idx.Tag.Get("db")
+		if col == "" {
+			continue
+		}
+		if _, ok := gomOption(idx.Tag, "createdBy"); ok {
+			out = append(out, actorField{col, idx.Index, idx.Type, true})
+		}
+		if _, ok := gomOption(idx.Tag, "updatedBy"); ok {
+			out = append(out, actorField{col, idx.Index, idx.Type, false})
+		}
+	}
+	return out
+}
+
+// fillActor 插入时填充零值的 createdBy / updatedBy，更新时覆盖 updatedBy
+func (m *ConDB) fillActor(rv reflect.Value, create bool) error {
+	fields := actorFields(rv.Type())
+	if !create {
+		fields = updatedByOnly(fields)
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	// 严格模式已由 requireActor 检查
+	actor, ok := ActorFrom(m.context())
+	if !ok {
+		return nil
+	}
+	for _, f := range fields {
+		fv := rv.FieldByIndex(f.index)
+		if !fv.CanSet() || (create && !fv.IsZero()) {
+			continue
+		}
+		v, err := convertValue(actor, f.typ, "")
+		if err != nil {
+			return fmt.Errorf("gom: actor %v for column %s: %v", actor, f.column, err)
+		}
+		fv.Set(v)
+	}
+	return nil
+}
+
+// actorMap 为 UpdateMap 补充未给出的 updatedBy 列，列名取自登记的表
+func (m *ConDB) actorMap(table string, data map[string]interface{}) map[string]interface{} {
+	v, ok := actorTables.Load(table)
+	if !ok {
+		return data
+	}
+	actor, ok := ActorFrom(m.context())
+	if !ok {
+		return data
+	}
+	out := make(map[string]interface{}, len(data)+1)
+	for k, val := range data {
+		out[k] = val
+	}
+	for _, col := range v.([]string) {
+		if _, ok := out[col]; !ok {
+			out[col] = actor
+		}
+	}
+	return out
+}
+
+func updatedByOnly(fields []actorField) []actorField {
+	out := fields[:0:0]
+	for _, f := range fields {
+		if !f.create {
+			out = append(out, f)
+		}
+	}
+	return out
+}
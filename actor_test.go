@@ -0,0 +1,119 @@
+package gom
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type actorDoc struct {
+	Id        int64  `db:"id"`
+	Title     string `db:"title"`
+	CreatedBy int64  `db:"created_by" gom:"createdBy"`
+	UpdatedBy int64  `db:"updated_by" gom:"updatedBy"`
+}
+
+type actorShardDoc struct {
+	Id        int64  `db:"id"`
+	OwnerId   int64  `db:"owner_id"`
+	UpdatedBy string `db:"updated_by" gom:"updatedBy"`
+}
+
+func init() {
+	RegisterModel(actorDoc{}, actorShardDoc{})
+	RegisterShard(actorShardDoc{}, ShardConfig{Key: "owner_id", Count: 4})
+}
+
+func TestStrictActor(t *testing.T) {
+	table := getTable(actorDoc{})
+	data := map[string]interface{}{"title": "x"}
+	tests := []struct {
+		name string
+		run  func(db *ConDB) error
+	}{
+		{"insert", func(db *ConDB) error { return db.Insert(&actorDoc{Title: "x"}) }},
+		{"insert skipping hooks", func(db *ConDB) error { return db.SkipHooks().Insert(&actorDoc{Title: "x"}) }},
+		{"save", func(db *ConDB) error { return db.Save(&actorDoc{Id: 1}) }},
+		{"update", func(db *ConDB) error { return db.Table(table).Where("id = ?", 1).Update("title = ?", "x") }},
+		{"update map on table", func(db *ConDB) error { return db.Table(table).Where("id = ?", 1).UpdateMap(data) }},
+		{"update map skipping hooks", func(db *ConDB) error {
+			return db.SkipHooks().Model(actorDoc{}).Where("id = ?", 1).UpdateMap(data)
+		}},
+		{"delete chain", func(db *ConDB) error { return db.Table(table).Where("id = ?", 1).Delete() }},
+		{"delete object", func(db *ConDB) error { return db.Delete(&actorDoc{Id: 1}) }},
+		{"repository delete", func(db *ConDB) error { return NewRepository[actorDoc](db).Delete(db.context(), 1) }},
+		{"sharded delete", func(db *ConDB) error { return db.Delete(&actorShardDoc{Id: 1, OwnerId: 2}) }},
+	}
+	for _, audit := range []bool{false, true} {
+		for _, tt := range tests {
+			name := tt.name
+			if audit {
+				name += " audited"
+			}
+			t.Run(name, func(t *testing.T) {
+				root := &ConDB{}
+				root.RequireActor(true)
+				if audit {
+					root.EnableAudit(func(*ConDB, *AuditEntry) error { return nil })
+				}
+				dry := root.DryRun()
+				if err := tt.run(dry); !errors.Is(err, ErrNoActor) {
+					t.Errorf("err = %v, want ErrNoActor", err)
+				}
+				if n := len(dry.Statements()); n != 0 {
+					t.Errorf("%d statements sent without an actor", n)
+				}
+
+				// 有操作人时正常执行
+				ctx := WithActor(context.Background(), 9)
+				if err := tt.run(dry.WithContext(ctx)); err != nil {
+					t.Errorf("with actor: %v", err)
+				}
+			})
+		}
+	}
+}
+
+func TestActorFill(t *testing.T) {
+	table := getTable(actorDoc{})
+	ctx := WithActor(context.Background(), 9)
+	tests := []struct {
+		name string
+		run  func(db *ConDB) error
+		sql  string
+		args []interface{}
+	}{
+		{
+			"insert",
+			func(db *ConDB) error { return db.WithContext(ctx).Insert(&actorDoc{Title: "x"}) },
+			"INSERT INTO " + table + " (title,created_by,updated_by) VALUES (?,?,?)",
+			[]interface{}{"x", int64(9), int64(9)},
+		},
+		{
+			"update map",
+			func(db *ConDB) error {
+				return db.WithContext(ctx).Table(table).Where("id = ?", 1).UpdateMap(map[string]interface{}{"title": "x"})
+			},
+			"UPDATE " + table + " SET title = ?, updated_by = ? WHERE id = ?",
+			[]interface{}{"x", 9, 1},
+		},
+		{
+			"no actor outside strict mode",
+			func(db *ConDB) error { return db.Insert(&actorDoc{Title: "x"}) },
+			"INSERT INTO " + table + " (title,created_by,updated_by) VALUES (?,?,?)",
+			[]interface{}{"x", int64(0), int64(0)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := (&ConDB{}).ToSQL(tt.run)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sql != tt.sql || !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("got %q %v\nwant %q %v", sql, args, tt.sql, tt.args)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package gom
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// AuditEntry describes one audited change. For updates Before and After
+// hold only the columns that changed; for deletes Before holds the whole
+// row and After is nil.
+type AuditEntry struct {
+	Table  string
+	PK     interface{}
+	Op     string // "update" or "delete"
+	Before map[string]interface{}
+	After  map[string]interface{}
+	Actor  interface{} // from WithActor, nil when absent
+	At     time.Time
+}
+
+// AuditSink stores an entry. db is bound to the transaction of the
+// audited change, so a failing sink rolls the change back.
+type AuditSink func(db *ConDB, entry *AuditEntry) error
+
+type auditConfig struct {
+	sink   AuditSink
+	tables map[string]bool // 为空时审计所有表
+}
+
+func (a *auditConfig) covers(table string) bool {
+	return len(a.tables) == 0 || a.tables[table]
+}
+
+// EnableAudit records struct updates (Save, Repository.Update) and deletes
+// by primary key (Delete(obj), Repository.Delete) on tables, or on every
+// table when none is given. The prior row is loaded with FOR UPDATE in the
+// same transaction, which is started here when the chain has none.
+func (m *ConDB) EnableAudit(sink AuditSink, tables ...string) {
+	cfg := &auditConfig{sink: sink, tables: make(map[string]bool)}
+	for _, t := range tables {
+		cfg.tables[t] = true
+	}
+	m.root().audit = cfg
+}
+
+// DisableAudit turns auditing off.
+func (m *ConDB) DisableAudit() {
+	m.root().audit = nil
+}
+
+// AuditTable returns a sink inserting into table, which needs the columns
+// table_name, pk, op, before_data, after_data (JSON text), actor and
+// created_at.
+func AuditTable(table string) AuditSink {
+	return func(db *ConDB, e *AuditEntry) error {
+		before, err := encodeJSON(e.Before)
+		if err != nil {
+			return err
+		}
+		after, err := encodeJSON(e.After)
+		if err != nil {
+			return err
+		}
+		sqlStr := fmt.Sprintf("INSERT INTO %s (table_name,pk,op,before_data,after_data,actor,created_at) VALUES (?,?,?,?,?,?,?)", table)
+		args := []interface{}{e.Table, parseString(e.PK), e.Op, before, after, e.Actor, e.At}
+		db.trace(sqlStr, args)
+		_, err = db.exec(StmtInsert, table, sqlStr, args)
+		return err
+	}
+}
+
+// withAudit 在事务中加载旧行（FOR UPDATE）、执行 write 并写入审计记录；
+// table 为路由前的逻辑表名，after 为更新后的结构体，删除时为零值
+func (m *ConDB) withAudit(table, op string, t reflect.Type, pk string, id interface{}, after reflect.Value, write func() error) error {
+	a := m.root().audit
+	if a == nil || !a.covers(table) || t == nil || t.Kind() != reflect.Struct {
+		return write()
+	}
+	if err := m.routeShardSingle(); err != nil {
+		return err
+	}
+	if m.tx != nil {
+		return m.writeAudit(a, table, op, t, pk, id, after, write)
+	}
+
+	tx, err := m.Db.BeginTx(m.context(), nil)
+	if err != nil {
+		return err
+	}
+	m.tx = tx
+	defer func() { m.tx = nil }()
+	if err := m.writeAudit(a, table, op, t, pk, id, after, write); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (m *ConDB) writeAudit(a *auditConfig, table, op string, t reflect.Type, pk string, id interface{}, after reflect.Value, write func() error) error {
+	// 旧行与写入使用同一事务、上下文和租户范围
+	q := m.root().Tx(m.tx)
+	q.ctx = m.ctx
+	q.tenant, q.skipTenant, q.primary = m.tenant, m.skipTenant, m.primary
+	q.builder.From(m.builder.table)
+	q.builder.Where(pk+" = ?", id)
+	before := reflect.New(t)
+	found := true
+	if err := q.GetForUpdate(before.Interface()); err == sql.ErrNoRows {
+		found = false
+	} else if err != nil {
+		return err
+	}
+
+	if err := write(); err != nil {
+		return err
+	}
+	if !found {
+		return nil
+	}
+
+	entry := &AuditEntry{Table: table, PK: id, Op: op, At: m.now()}
+	entry.Actor, _ = ActorFrom(m.context())
+	if after.IsValid() {
+		entry.Before, entry.After = diffFields(before.Elem(), after)
+		if len(entry.Before) == 0 {
+			return nil
+		}
+	} else {
+		entry.Before = fieldValues(before.Elem())
+	}
+	return a.sink(q, entry)
+}
+
+// fieldValues 按列名取出所有 db 列的值
+func fieldValues(v reflect.Value) map[string]interface{} {
+	out := make(map[string]interface{})
+	for _, idx := range getFieldMap(v.Type()) {
+		if col := idx.Tag.Get("db"); col != "" {
+			out[col] = v.FieldByIndex(idx.Index).Interface()
+		}
+	}
+	return out
+}
+
+// diffFields 返回发生变化的列的旧值与新值
+func diffFields(before, after reflect.Value) (map[string]interface{}, map[string]interface{}) {
+	b := make(map[string]interface{})
+	a := make(map[string]interface{})
+	for _, idx := range getFieldMap(before.Type()) {
+		col := idx.Tag.Get("db")
+		if col == "" {
+			continue
+		}
+		old := before.FieldByIndex(idx.Index).Interface()
+		cur := after.FieldByIndex(idx.Index).Interface()
+		if sameValue(old, cur) {
+			continue
+		}
+		b[col], a[col] = old, cur
+	}
+	return b, a
+}
+
+// sameValue 比较两个字段值，time.Time 按时刻比较
+func sameValue(x, y interface{}) bool {
+	if tx, ok := x.(time.Time); ok {
+		if ty, ok := y.(time.Time); ok {
+			return tx.Equal(ty)
+		}
+	}
+	return reflect.DeepEqual(x, y)
+}
@@ -0,0 +1,178 @@
+package gom
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type auditDoc struct {
+	Id    int64  `db:"id"`
+	Title string `db:"title"`
+	Body  string `db:"body"`
+}
+
+func auditDB(t *testing.T, sink AuditSink, tables ...string) (*ConDB, *testDriver) {
+	d := &testDriver{
+		cols:   []string{"id", "title", "body"},
+		tables: map[string][][]driver.Value{getTable(auditDoc{}): {{int64(1), "old", "text"}}},
+	}
+	db := &ConDB{Db: d.open(t)}
+	db.SetClock(func() time.Time { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) })
+	db.EnableAudit(sink, tables...)
+	return db, d
+}
+
+func TestAudit(t *testing.T) {
+	table := getTable(auditDoc{})
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		noRow bool
+		run   func(db *ConDB) error
+		entry *AuditEntry
+		stmts []string
+	}{
+		{
+			"save",
+			false,
+			func(db *ConDB) error {
+				return db.WithContext(WithActor(context.Background(), "amy")).Save(&auditDoc{Id: 1, Title: "new", Body: "text"})
+			},
+			&AuditEntry{Table: table, PK: int64(1), Op: "update", Before: map[string]interface{}{"title": "old"}, After: map[string]interface{}{"title": "new"}, Actor: "amy", At: at},
+			[]string{"BEGIN", "SELECT * FROM " + table + " WHERE id = ? for update", "UPDATE " + table + " SET title = ?, body = ? WHERE id = ?", "COMMIT"},
+		},
+		{
+			"save without changes",
+			false,
+			func(db *ConDB) error { return db.Save(&auditDoc{Id: 1, Title: "old", Body: "text"}) },
+			nil,
+			[]string{"BEGIN", "SELECT * FROM " + table + " WHERE id = ? for update", "UPDATE " + table + " SET title = ?, body = ? WHERE id = ?", "COMMIT"},
+		},
+		{
+			"delete",
+			false,
+			func(db *ConDB) error { return db.Delete(&auditDoc{Id: 1}) },
+			&AuditEntry{Table: table, PK: int64(1), Op: "delete", Before: map[string]interface{}{"id": int64(1), "title": "old", "body": "text"}, At: at},
+			[]string{"BEGIN", "SELECT * FROM " + table + " WHERE id = ? for update", "DELETE FROM " + table + " WHERE id = ?", "COMMIT"},
+		},
+		{
+			"repository delete",
+			false,
+			func(db *ConDB) error { return NewRepository[auditDoc](db).Delete(context.Background(), int64(1)) },
+			&AuditEntry{Table: table, PK: int64(1), Op: "delete", Before: map[string]interface{}{"id": int64(1), "title": "old", "body": "text"}, At: at},
+			[]string{"BEGIN", "SELECT * FROM " + table + " WHERE id = ? for update", "DELETE FROM " + table + " WHERE id = ?", "COMMIT"},
+		},
+		{
+			"missing row",
+			true,
+			func(db *ConDB) error { return db.Delete(&auditDoc{Id: 1}) },
+			nil,
+			[]string{"BEGIN", "SELECT * FROM " + table + " WHERE id = ? for update", "DELETE FROM " + table + " WHERE id = ?", "COMMIT"},
+		},
+		{
+			"chain delete is not audited",
+			false,
+			func(db *ConDB) error { return db.Table(table).Where("id = ?", 1).Delete() },
+			nil,
+			[]string{"DELETE FROM " + table + " WHERE id = ?"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []*AuditEntry
+			db, d := auditDB(t, func(_ *ConDB, e *AuditEntry) error { got = append(got, e); return nil })
+			if tt.noRow {
+				d.tables = nil
+			}
+			if err := tt.run(db); err != nil {
+				t.Fatal(err)
+			}
+			if tt.entry == nil && len(got) != 0 || tt.entry != nil && (len(got) != 1 || !reflect.DeepEqual(got[0], tt.entry)) {
+				t.Errorf("entries = %+v, want %+v", got, tt.entry)
+			}
+			if s := d.statements(); !reflect.DeepEqual(s, tt.stmts) {
+				t.Errorf("statements = %q\nwant %q", s, tt.stmts)
+			}
+		})
+	}
+}
+
+func TestAuditSinkFailureRollsBack(t *testing.T) {
+	boom := errors.New("boom")
+	db, d := auditDB(t, func(*ConDB, *AuditEntry) error { return boom })
+	if err := db.Save(&auditDoc{Id: 1, Title: "new"}); err != boom {
+		t.Fatalf("err = %v, want %v", err, boom)
+	}
+	s := d.statements()
+	if len(s) == 0 || s[len(s)-1] != "ROLLBACK" {
+		t.Errorf("statements = %q, want a rollback", s)
+	}
+}
+
+func TestAuditInCallerTx(t *testing.T) {
+	db, d := auditDB(t, AuditTable("audit_log"))
+	tx := db.TxBegin()
+	if err := tx.Save(&auditDoc{Id: 1, Title: "new", Body: "text"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	s := d.statements()
+	want := []string{
+		"BEGIN",
+		"SELECT * FROM tb_audit_doc WHERE id = ? for update",
+		"UPDATE tb_audit_doc SET title = ?, body = ? WHERE id = ?",
+		"INSERT INTO audit_log (table_name,pk,op,before_data,after_data,actor,created_at) VALUES (?,?,?,?,?,?,?)",
+		"COMMIT",
+	}
+	if !reflect.DeepEqual(s, want) {
+		t.Fatalf("statements = %q\nwant %q", s, want)
+	}
+	args := d.args[3]
+	if args[0] != "tb_audit_doc" || args[1] != "1" || args[2] != "update" || args[3] != `{"title":"old"}` || args[4] != `{"title":"new"}` {
+		t.Errorf("audit row = %v", args)
+	}
+}
+
+func TestAuditTableFilter(t *testing.T) {
+	var got int
+	db, d := auditDB(t, func(*ConDB, *AuditEntry) error { got++; return nil }, "tb_other")
+	if err := db.Delete(&auditDoc{Id: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if got != 0 || len(d.statements()) != 1 {
+		t.Errorf("uncovered table audited: %d entries, statements %q", got, d.statements())
+	}
+}
+
+type auditShardDoc struct {
+	Id     int64  `db:"id"`
+	UserId int64  `db:"user_id"`
+	Title  string `db:"title"`
+}
+
+func TestAuditShardedTable(t *testing.T) {
+	table := getTable(auditShardDoc{})
+	RegisterShardTable(table, ShardConfig{Key: "user_id", Count: 2})
+	d := &testDriver{
+		cols:   []string{"id", "user_id", "title"},
+		tables: map[string][][]driver.Value{table + "_01": {{int64(1), int64(3), "old"}}},
+	}
+	db := &ConDB{Db: d.open(t)}
+	var got []*AuditEntry
+	db.EnableAudit(func(_ *ConDB, e *AuditEntry) error { got = append(got, e); return nil }, table)
+	if err := db.Save(&auditShardDoc{Id: 1, UserId: 3, Title: "new"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Table != table {
+		t.Fatalf("entries = %+v, want one for the logical table %s", got, table)
+	}
+	if s := d.statements(); len(s) != 4 || s[1] != "SELECT * FROM "+table+"_01 WHERE id = ? for update" {
+		t.Errorf("statements = %q", s)
+	}
+}
@@ -0,0 +1,170 @@
+package gom
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// gomOption 判断 gom 标签（逗号分隔）是否包含 name，返回冒号后的参数，
+// 如 gom:"autoCreateTime:milli" => "milli", true
+func gomOption(tag reflect.StructTag, name string) (string, bool) {
+	for _, opt := range strings.Split(tag.Get("gom"), ",") {
+		key, arg := strings.TrimSpace(opt), ""
+		if i := strings.IndexByte(key, ':'); i >= 0 {
+			key, arg = key[:i], key[i+1:]
+		}
+		if key == name {
+			return arg, true
+		}
+	}
+	return "", false
+}
+
+// SetClock replaces the clock used for autoCreateTime / autoUpdateTime
+// columns, mainly so tests get stable values. nil restores time.Now.
+func (m *ConDB) SetClock(now func() time.Time) {
+	m.root().clock = now
+}
+
+func (m *ConDB) now() time.Time {
+	if c := m.root().clock; c != nil {
+		return c()
+	}
+	return time.Now()
+}
+
+// SkipHooks makes the following Insert, Save or UpdateMap write the struct
+// as is: PreInsert / PreUpdate are not called and automatic columns such as
+// autoCreateTime / autoUpdateTime are left alone.
+func (m *ConDB) SkipHooks() *ConDB {
+	db := m
+	if m.parent == nil {
+		db = m.clone()
+	}
+	db.skipHooks = true
+	return db
+}
+
+// autoTimeField 一个自动时间戳列
+type autoTimeField struct {
+	column string
+	index  []int
+	typ    reflect.Type
+	tag    reflect.StructTag
+	create bool   // autoCreateTime，否则为 autoUpdateTime
+	unit   string // "", second, milli, nano
+}
+
+// autoTimeFields 收集带 autoCreateTime / autoUpdateTime 标签的 db 列
+func autoTimeFields(t reflect.Type) []autoTimeField {
+	var out []autoTimeField
+	for col, idx := range getFieldMap(t) {
+		if idx.Tag.Get("db") == "" {
+			continue
+		}
+		if unit, ok := gomOption(idx.Tag, "autoCreateTime"); ok {
+			out = append(out, autoTimeField{col, idx.Index, idx.Type, idx.Tag, true, unit})
+		}
+		if unit, ok := gomOption(idx.Tag, "autoUpdateTime"); ok {
+			out = append(out, autoTimeField{col, idx.Index, idx.Type, idx.Tag, false, unit})
+		}
+	}
+	return out
+}
+
+// value 按字段类型生成时间值：time.Time 按精度截断，整数为 Unix 时间戳，
+// 字符串按 type 标签格式化
+func (f autoTimeField) value(now time.Time) (reflect.Value, bool) {
+	t := f.typ
+	if t.Kind() == reflect.Ptr {
+		v, ok := autoTimeField{typ: t.Elem(), tag: f.tag, unit: f.unit}.value(now)
+		if !ok {
+			return v, false
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(v)
+		return ptr, true
+	}
+
+	switch f.unit {
+	case "milli":
+		now = now.Truncate(time.Millisecond)
+	case "second", "unix":
+		now = now.Truncate(time.Second)
+	}
+
+	var n int64
+	switch f.unit {
+	case "milli":
+		n = now.UnixMilli()
+	case "nano":
+		n = now.UnixNano()
+	default:
+		n = now.Unix()
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return reflect.ValueOf(n).Convert(t), true
+	case reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return reflect.ValueOf(uint64(n)).Convert(t), true
+	case reflect.String:
+		layout := "2006-01-02 15:04:05"
+		if f.tag.Get("type") == "date" {
+			layout = "2006-01-02"
+		}
+		return reflect.ValueOf(now.Format(layout)).Convert(t), true
+	case reflect.Struct:
+		if t == reflect.TypeOf(time.Time{}) {
+			return reflect.ValueOf(now), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// touchTimestamps 填充结构体的时间戳字段：插入时只填零值，更新时覆盖 autoUpdateTime
+func touchTimestamps(rv reflect.Value, now time.Time, create bool) {
+	for _, f := range autoTimeFields(rv.Type()) {
+		if !create && f.create {
+			continue
+		}
+		fv := rv.FieldByIndex(f.index)
+		if !fv.CanSet() || (create && !fv.IsZero()) {
+			continue
+		}
+		if v, ok := f.value(now); ok {
+			fv.Set(v)
+		}
+	}
+}
+
+// touchMap 为 UpdateMap 补充未给出的 autoUpdateTime 列，需要先调用 Model
+func touchMap(t reflect.Type, data map[string]interface{}, now time.Time) map[string]interface{} {
+	var out map[string]interface{}
+	for _, f := range autoTimeFields(t) {
+		if f.create {
+			continue
+		}
+		col := f.tag.Get("db")
+		if _, ok := data[col]; ok {
+			continue
+		}
+		v, ok := f.value(now)
+		if !ok {
+			continue
+		}
+		if out == nil {
+			// 不修改调用方的 map
+			out = make(map[string]interface{}, len(data)+1)
+			for k, val := range data {
+				out[k] = val
+			}
+		}
+		out[col] = v.Interface()
+	}
+	if out == nil {
+		return data
+	}
+	return out
+}
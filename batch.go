@@ -0,0 +1,98 @@
+package gom
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// FindInBatches walks the rows matching the chain in primary key order,
+// loading at most size rows into out (a pointer to a struct slice) per
+// chunk and calling fn with the 1-based chunk number. Chunks are fetched
+// with "pk > last" rather than OFFSET. Returning ErrStop from fn ends the
+// walk without error.
+func (m *ConDB) FindInBatches(out interface{}, size int, fn func(batch int) error) error {
+	return m.findInBatches(out, size, false, func(_ *ConDB, batch int) error {
+		return fn(batch)
+	})
+}
+
+// FindInBatchesTx is FindInBatches with each chunk read and processed in
+// its own transaction, committed when fn returns nil or ErrStop and rolled
+// back otherwise. tx is bound to that transaction. When the chain already runs
+// in a transaction, every chunk uses it and nothing is committed here.
+func (m *ConDB) FindInBatchesTx(out interface{}, size int, fn func(tx *ConDB, batch int) error) error {
+	return m.findInBatches(out, size, true, fn)
+}
+
+func (m *ConDB) findInBatches(out interface{}, size int, ownTx bool, fn func(tx *ConDB, batch int) error) error {
+	if m.parent == nil {
+		return errors.New("Lack of ConDB objects")
+	}
+	if size <= 0 {
+		return errors.New("gom: batch size must be positive")
+	}
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return errors.New("gom: FindInBatches needs a pointer to slice")
+	}
+	if m.builder.table == "" {
+		m.builder.From(getTable(out))
+	}
+
+	sliceValue := rv.Elem()
+	eleType := sliceValue.Type().Elem()
+	_, pk, ok := findIDField(reflect.New(eleType).Elem())
+	if !ok {
+		return errors.New(`missing field tag db:"id"`)
+	}
+	pkIndex := getFieldMap(eleType)[strings.ToLower(pk)].Index
+
+	var last interface{}
+	for batch := 1; ; batch++ {
+		chunk := m.fork()
+		var tx *sql.Tx
+		if ownTx && m.tx == nil {
+			var err error
+			if tx, err = m.Db.BeginTx(m.context(), nil); err != nil {
+				return err
+			}
+			chunk.tx = tx
+		}
+		if last != nil {
+			// 含 OR 的条件先加括号，否则 pk > ? 只约束最后一个分支
+			chunk.builder.group()
+			chunk.builder.Where(pk+" > ?", last)
+		}
+		chunk.builder.OrderBy(pk + " ASC")
+		chunk.builder.Limit(0, int32(size))
+
+		sliceValue.Set(reflect.Zero(sliceValue.Type()))
+		err := chunk.Find(out)
+		n := sliceValue.Len()
+		if err == nil && n > 0 {
+			last = sliceValue.Index(n - 1).FieldByIndex(pkIndex).Interface()
+			var txdb *ConDB
+			if chunk.tx != nil {
+				txdb = m.root().Tx(chunk.tx)
+				txdb.ctx = m.ctx
+			}
+			err = fn(txdb, batch)
+		}
+		stop := err == ErrStop
+		if stop {
+			err = nil
+		}
+		if tx != nil {
+			if err != nil {
+				tx.Rollback()
+			} else {
+				err = tx.Commit()
+			}
+		}
+		if err != nil || stop || n < size {
+			return err
+		}
+	}
+}
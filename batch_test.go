@@ -0,0 +1,87 @@
+package gom
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+type batchItem struct {
+	Id   int64  `db:"id"`
+	Name string `db:"name"`
+}
+
+func TestFindInBatches(t *testing.T) {
+	tests := []struct {
+		name  string
+		chain func(db *ConDB) *ConDB
+		want  []string
+	}{
+		{
+			"where",
+			func(db *ConDB) *ConDB { return db.Where("name = ?", "a") },
+			[]string{
+				"SELECT * FROM tb_batch WHERE name = ? ORDER BY id ASC LIMIT 2 OFFSET 0",
+				"SELECT * FROM tb_batch WHERE name = ? AND id > ? ORDER BY id ASC LIMIT 2 OFFSET 0",
+			},
+		},
+		{
+			"or",
+			func(db *ConDB) *ConDB { return db.Where("name = ?", "a").Or("name = ?", "b") },
+			[]string{
+				"SELECT * FROM tb_batch WHERE name = ? OR name = ? ORDER BY id ASC LIMIT 2 OFFSET 0",
+				"SELECT * FROM tb_batch WHERE (name = ? OR name = ?) AND id > ? ORDER BY id ASC LIMIT 2 OFFSET 0",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &testDriver{
+				cols:   []string{"id", "name"},
+				tables: map[string][][]driver.Value{"tb_batch": {{int64(1), "a"}, {int64(4), "b"}}},
+			}
+			db := &ConDB{Db: d.open(t)}
+			var out []batchItem
+			var seen [][]int64
+			err := tt.chain(db.Table("tb_batch")).FindInBatches(&out, 2, func(batch int) error {
+				var ids []int64
+				for _, o := range out {
+					ids = append(ids, o.Id)
+				}
+				seen = append(seen, ids)
+				if batch == 2 {
+					return ErrStop
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := d.statements(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("statements = %q\nwant %q", got, tt.want)
+			}
+			if d.args[1][len(d.args[1])-1] != int64(4) {
+				t.Errorf("second batch starts after %v, want 4", d.args[1])
+			}
+			if len(seen) != 2 || !reflect.DeepEqual(seen[0], []int64{1, 4}) {
+				t.Errorf("batches = %v", seen)
+			}
+		})
+	}
+}
+
+func TestFindInBatchesStopsOnShortChunk(t *testing.T) {
+	d := &testDriver{
+		cols:   []string{"id", "name"},
+		tables: map[string][][]driver.Value{"tb_batch": {{int64(1), "a"}}},
+	}
+	db := &ConDB{Db: d.open(t)}
+	var out []batchItem
+	calls := 0
+	if err := db.Table("tb_batch").FindInBatches(&out, 2, func(int) error { calls++; return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 || len(d.statements()) != 1 {
+		t.Errorf("calls = %d, statements = %q; want one chunk", calls, d.statements())
+	}
+}
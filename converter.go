@@ -0,0 +1,133 @@
+package gom
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Converter maps a Go type to and from its database representation.
+type Converter struct {
+	FromDB func(raw interface{}) (interface{}, error)
+	ToDB   func(v interface{}) (interface{}, error)
+}
+
+var converters = struct {
+	sync.RWMutex
+	byType   map[reflect.Type]*Converter
+	byName   map[string]*Converter
+	byColumn map[string]func(raw interface{}) (interface{}, error) // map 结果按列名转换
+}{
+	byType:   make(map[reflect.Type]*Converter),
+	byName:   make(map[string]*Converter),
+	byColumn: make(map[string]func(raw interface{}) (interface{}, error)),
+}
+
+// RegisterConverter maps goType for every struct field of that type and for
+// map values written by UpdateMap. Map results (QueryMap, List, Raw().Scan
+// into a map) only know the driver type, usually []byte, so type converters
+// do not apply there; use RegisterResultConverter to opt columns in.
+// Either function may be nil to keep the default behaviour in that direction.
+func RegisterConverter(goType reflect.Type, fromDB func(raw interface{}) (interface{}, error), toDB func(v interface{}) (interface{}, error)) {
+	converters.Lock()
+	converters.byType[goType] = &Converter{FromDB: fromDB, ToDB: toDB}
+	converters.Unlock()
+}
+
+// RegisterNamedConverter registers a converter that fields select with a
+// conv:"name" tag, taking precedence over type converters.
+func RegisterNamedConverter(name string, fromDB func(raw interface{}) (interface{}, error), toDB func(v interface{}) (interface{}, error)) {
+	converters.Lock()
+	converters.byName[name] = &Converter{FromDB: fromDB, ToDB: toDB}
+	converters.Unlock()
+}
+
+// RegisterResultConverter converts the named column in map results
+// (QueryMap, QueryMaps, List, Raw().Scan into a map) with fromDB instead of
+// ConvertValueAuto. Columns are matched by name, case-insensitively, in
+// every table; fromDB receives the raw driver value, which may be nil.
+func RegisterResultConverter(column string, fromDB func(raw interface{}) (interface{}, error)) {
+	converters.Lock()
+	converters.byColumn[strings.ToLower(column)] = fromDB
+	converters.Unlock()
+}
+
+// convertColumnAuto 转换 map 结果中的列：有按列名注册的转换器时使用它，否则 ConvertValueAuto
+func convertColumnAuto(column string, raw interface{}) (interface{}, error) {
+	converters.RLock()
+	fromDB := converters.byColumn[strings.ToLower(column)]
+	converters.RUnlock()
+	if fromDB == nil {
+		return ConvertValueAuto(raw), nil
+	}
+	v, err := fromDB(raw)
+	if err != nil {
+		return nil, fmt.Errorf("gom: column %s: %w", column, err)
+	}
+	return v, nil
+}
+
+// lookupConverter 先按 conv 标签，再按类型查找
+func lookupConverter(t reflect.Type, tag reflect.StructTag) *Converter {
+	converters.RLock()
+	defer converters.RUnlock()
+	if name := tag.Get("conv"); name != "" {
+		if c, ok := converters.byName[name]; ok {
+			return c
+		}
+	}
+	if t != nil {
+		return converters.byType[t]
+	}
+	return nil
+}
+
+// fromDBConverter 使用注册的转换器读取列值；ok 为 false 表示没有适用的转换器
+func fromDBConverter(raw interface{}, targetType reflect.Type, tag reflect.StructTag) (reflect.Value, bool, error) {
+	c := lookupConverter(targetType, tag)
+	if c == nil || c.FromDB == nil {
+		return reflect.Value{}, false, nil
+	}
+	v, err := c.FromDB(raw)
+	if err != nil {
+		return reflect.Zero(targetType), true, err
+	}
+	if v == nil {
+		return reflect.Zero(targetType), true, nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Type() == targetType {
+		return rv, true, nil
+	}
+	if rv.Type().ConvertibleTo(targetType) {
+		return rv.Convert(targetType), true, nil
+	}
+	if targetType.Kind() == reflect.Ptr && rv.Type().ConvertibleTo(targetType.Elem()) {
+		ptr := reflect.New(targetType.Elem())
+		ptr.Elem().Set(rv.Convert(targetType.Elem()))
+		return ptr, true, nil
+	}
+	return reflect.Zero(targetType), true, fmt.Errorf("gom: converter returned %s for %s", rv.Type(), targetType)
+}
+
+// toDBConverter 使用注册的转换器生成写入值
+func toDBConverter(v interface{}, tag reflect.StructTag) (interface{}, bool, error) {
+	if v == nil && tag.Get("conv") == "" {
+		return nil, false, nil
+	}
+	c := lookupConverter(reflect.TypeOf(v), tag)
+	if c == nil {
+		// 非 nil 指针按元素类型再查一次
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && !rv.IsNil() {
+			if c = lookupConverter(rv.Elem().Type(), ""); c != nil {
+				v = rv.Elem().Interface()
+			}
+		}
+	}
+	if c == nil || c.ToDB == nil {
+		return nil, false, nil
+	}
+	out, err := c.ToDB(v)
+	return out, true, err
+}
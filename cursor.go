@@ -0,0 +1,237 @@
+package gom
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("gom: invalid cursor")
+
+// CursorPage describes a keyset page. Pass Next to After and Prev to Before
+// to move forward or backward.
+type CursorPage struct {
+	Next    string
+	Prev    string
+	HasNext bool
+	HasPrev bool
+}
+
+type cursorState struct {
+	token  string
+	before bool
+	keys   []orderKey
+}
+
+// SortKeys sets the keyset sort columns for CursorFind, e.g.
+// SortKeys("created_at desc"). The primary key is appended as tie-breaker.
+func (m *ConDB) SortKeys(keys ...string) *ConDB {
+	db := m
+	if m.parent == nil {
+		db = m.clone()
+	}
+	if db.cursor == nil {
+		db.cursor = &cursorState{}
+	}
+	db.cursor.keys = parseOrderBy(strings.Join(keys, ","))
+	return db
+}
+
+// After continues a keyset scan past the row encoded in cursor.
+func (m *ConDB) After(cursor string) *ConDB {
+	return m.seek(cursor, false)
+}
+
+// Before scans back from the row encoded in cursor.
+func (m *ConDB) Before(cursor string) *ConDB {
+	return m.seek(cursor, true)
+}
+
+func (m *ConDB) seek(cursor string, before bool) *ConDB {
+	db := m
+	if m.parent == nil {
+		db = m.clone()
+	}
+	if db.cursor == nil {
+		db.cursor = &cursorState{}
+	}
+	db.cursor.token = cursor
+	db.cursor.before = before
+	return db
+}
+
+// CursorFind loads up to size rows into out (a pointer to a struct slice)
+// using keyset pagination instead of OFFSET, so deep pages stay cheap and
+// deleted rows never shift page boundaries.
+func (m *ConDB) CursorFind(size int, out interface{}) (CursorPage, error) {
+	var page CursorPage
+	if m.parent == nil {
+		return page, errors.New("Lack of ConDB objects")
+	}
+	if size <= 0 {
+		return page, errors.New("gom: cursor page size must be positive")
+	}
+	sliceValue := reflect.ValueOf(out).Elem()
+	eleType := sliceValue.Type().Elem()
+	fieldMap := getFieldMap(eleType)
+
+	state := m.cursor
+	if state == nil {
+		state = &cursorState{}
+	}
+	keys := state.keys
+	if len(keys) == 0 {
+		keys = parseOrderBy(m.builder.orderBy)
+	}
+	keys = withTieBreaker(keys, eleType)
+	for _, k := range keys {
+		if _, ok := fieldMap[strings.ToLower(k.column)]; !ok {
+			return page, fmt.Errorf("gom: sort key %s is not mapped on %s", k.column, eleType)
+		}
+	}
+
+	// 向前翻页时反转排序，取完后再倒序
+	scanKeys := keys
+	if state.before {
+		scanKeys = make([]orderKey, len(keys))
+		for i, k := range keys {
+			scanKeys[i] = orderKey{k.column, !k.desc}
+		}
+	}
+
+	if state.token != "" {
+		values, err := decodeCursor(state.token, len(keys))
+		if err != nil {
+			return page, err
+		}
+		expr, args := keysetCondition(scanKeys, values)
+		m.builder.group()
+		m.builder.Where(expr, args...)
+	}
+
+	order := make([]string, len(scanKeys))
+	for i, k := range scanKeys {
+		order[i] = k.column + " ASC"
+		if k.desc {
+			order[i] = k.column + " DESC"
+		}
+	}
+	m.builder.OrderBy(strings.Join(order, ", "))
+	m.builder.Limit(0, int32(size+1))
+
+	list := reflect.New(sliceValue.Type())
+	if err := m.Find(list.Interface()); err != nil {
+		return page, err
+	}
+	items := list.Elem()
+	more := items.Len() > size
+	if more {
+		items = items.Slice(0, size)
+	}
+	if state.before {
+		n := items.Len()
+		rev := reflect.MakeSlice(items.Type(), n, n)
+		for i := 0; i < n; i++ {
+			rev.Index(i).Set(items.Index(n - 1 - i))
+		}
+		items = rev
+		page.HasPrev = more
+		page.HasNext = state.token != ""
+	} else {
+		page.HasNext = more
+		page.HasPrev = state.token != ""
+	}
+
+	if n := items.Len(); n > 0 {
+		var err error
+		if page.Prev, err = encodeCursor(items.Index(0), keys, fieldMap); err != nil {
+			return page, err
+		}
+		if page.Next, err = encodeCursor(items.Index(n-1), keys, fieldMap); err != nil {
+			return page, err
+		}
+	}
+	sliceValue.Set(reflect.AppendSlice(sliceValue, items))
+	return page, nil
+}
+
+// withTieBreaker 在排序键末尾追加主键，保证顺序唯一
+func withTieBreaker(keys []orderKey, t reflect.Type) []orderKey {
+	pk := "id"
+	if _, col, ok := findIDField(reflect.New(t).Elem()); ok {
+		pk = col
+	}
+	desc := false
+	for _, k := range keys {
+		if strings.EqualFold(k.column, pk) {
+			return keys
+		}
+		desc = k.desc
+	}
+	return append(append([]orderKey{}, keys...), orderKey{pk, desc})
+}
+
+// keysetCondition 展开为 (a > ?) OR (a = ? AND b > ?) ...，兼容混合升降序
+func keysetCondition(keys []orderKey, values []interface{}) (string, []interface{}) {
+	var parts []string
+	var args []interface{}
+	for i, k := range keys {
+		var conds []string
+		for j := 0; j < i; j++ {
+			conds = append(conds, keys[j].column+" = ?")
+			args = append(args, values[j])
+		}
+		op := " > ?"
+		if k.desc {
+			op = " < ?"
+		}
+		conds = append(conds, k.column+op)
+		args = append(args, values[i])
+		parts = append(parts, "("+strings.Join(conds, " AND ")+")")
+	}
+	return "(" + strings.Join(parts, " OR ") + ")", args
+}
+
+func encodeCursor(row reflect.Value, keys []orderKey, fieldMap map[string]fieldIndex) (string, error) {
+	values := make([]interface{}, len(keys))
+	for i, k := range keys {
+		v := row.FieldByIndex(fieldMap[strings.ToLower(k.column)].Index).Interface()
+		if t, ok := v.(time.Time); ok {
+			v = t.Format("2006-01-02 15:04:05.999999")
+		}
+		values[i] = v
+	}
+	b, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeCursor(token string, n int) ([]interface{}, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var values []interface{}
+	if err := dec.Decode(&values); err != nil || len(values) != n {
+		return nil, ErrInvalidCursor
+	}
+	for i, v := range values {
+		if num, ok := v.(json.Number); ok {
+			if n, err := num.Int64(); err == nil {
+				values[i] = n
+			} else if f, err := num.Float64(); err == nil {
+				values[i] = f
+			}
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,143 @@
+package gom
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestKeysetCondition(t *testing.T) {
+	tests := []struct {
+		name   string
+		keys   []orderKey
+		values []interface{}
+		want   string
+		args   []interface{}
+	}{
+		{"single asc", []orderKey{{"id", false}}, []interface{}{5}, "((id > ?))", []interface{}{5}},
+		{"single desc", []orderKey{{"id", true}}, []interface{}{5}, "((id < ?))", []interface{}{5}},
+		{
+			"mixed", []orderKey{{"score", true}, {"id", false}}, []interface{}{90, 7},
+			"((score < ?) OR (score = ? AND id > ?))", []interface{}{90, 90, 7},
+		},
+		{
+			"three keys", []orderKey{{"a", false}, {"b", false}, {"c", true}}, []interface{}{1, 2, 3},
+			"((a > ?) OR (a = ? AND b > ?) OR (a = ? AND b = ? AND c < ?))", []interface{}{1, 1, 2, 1, 2, 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args := keysetCondition(tt.keys, tt.values)
+			if got != tt.want || !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("keysetCondition = %q, %v; want %q, %v", got, args, tt.want, tt.args)
+			}
+		})
+	}
+}
+
+type cursorItem struct {
+	Id      int64     `db:"id"`
+	Name    string    `db:"name"`
+	Score   float64   `db:"score"`
+	Created time.Time `db:"created_at"`
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	fieldMap := getFieldMap(reflect.TypeOf(cursorItem{}))
+	created := time.Date(2024, 1, 2, 3, 4, 5, 678000000, time.UTC)
+	row := reflect.ValueOf(cursorItem{Id: 1 << 60, Name: "a,b", Score: 2.5, Created: created})
+
+	tests := []struct {
+		name string
+		keys []orderKey
+		want []interface{}
+	}{
+		{"int", []orderKey{{"id", false}}, []interface{}{int64(1 << 60)}},
+		{"string and id", []orderKey{{"name", false}, {"id", false}}, []interface{}{"a,b", int64(1 << 60)}},
+		{"float", []orderKey{{"score", true}}, []interface{}{2.5}},
+		{"time", []orderKey{{"created_at", true}}, []interface{}{"2024-01-02 03:04:05.678"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := encodeCursor(row, tt.keys, fieldMap)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := decodeCursor(token, len(tt.keys))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("round trip = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	valid, _ := encodeCursor(reflect.ValueOf(cursorItem{Id: 1}), []orderKey{{"id", false}}, getFieldMap(reflect.TypeOf(cursorItem{})))
+	tests := []struct {
+		name  string
+		token string
+		n     int
+	}{
+		{"not base64", "!!!", 1},
+		{"not json", "bm9wZQ", 1},
+		{"wrong length", valid, 2},
+	}
+	for _, tt := range tests {
+		if _, err := decodeCursor(tt.token, tt.n); err != ErrInvalidCursor {
+			t.Errorf("%s: err = %v, want ErrInvalidCursor", tt.name, err)
+		}
+	}
+}
+
+func TestCursorFindWithOr(t *testing.T) {
+	token, _ := encodeCursor(reflect.ValueOf(cursorItem{Id: 9}), []orderKey{{"id", false}}, getFieldMap(reflect.TypeOf(cursorItem{})))
+	dry := (&ConDB{}).DryRun()
+	var out []cursorItem
+	if _, err := dry.Table("tb_item").Where("name = ?", "a").Or("name = ?", "b").After(token).CursorFind(2, &out); err != nil {
+		t.Fatal(err)
+	}
+	want := "SELECT * FROM tb_item WHERE (name = ? OR name = ?) AND ((id > ?)) ORDER BY id ASC LIMIT 3 OFFSET 0"
+	if stmts := dry.Statements(); len(stmts) != 1 || stmts[0].SQL != want {
+		t.Errorf("statements = %v, want %q", stmts, want)
+	}
+}
+
+func TestCursorFindSQL(t *testing.T) {
+	token, _ := encodeCursor(reflect.ValueOf(cursorItem{Id: 9, Score: 1.5}), []orderKey{{"score", true}, {"id", true}}, getFieldMap(reflect.TypeOf(cursorItem{})))
+	tests := []struct {
+		name   string
+		before bool
+		want   string
+	}{
+		{"after", false, "SELECT * FROM tb_item WHERE ((score < ?) OR (score = ? AND id < ?)) ORDER BY score DESC, id DESC LIMIT 3 OFFSET 0"},
+		{"before", true, "SELECT * FROM tb_item WHERE ((score > ?) OR (score = ? AND id > ?)) ORDER BY score ASC, id ASC LIMIT 3 OFFSET 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dry := (&ConDB{}).DryRun()
+			db := dry.Table("tb_item").SortKeys("score desc")
+			if tt.before {
+				db = db.Before(token)
+			} else {
+				db = db.After(token)
+			}
+			var out []cursorItem
+			if _, err := db.CursorFind(2, &out); err != nil {
+				t.Fatal(err)
+			}
+			stmts := dry.Statements()
+			if len(stmts) != 1 {
+				t.Fatalf("got %d statements, want 1", len(stmts))
+			}
+			if stmts[0].SQL != tt.want {
+				t.Errorf("SQL = %q, want %q", stmts[0].SQL, tt.want)
+			}
+			if want := []interface{}{1.5, 1.5, int64(9)}; !reflect.DeepEqual(stmts[0].Args, want) {
+				t.Errorf("args = %v, want %v", stmts[0].Args, want)
+			}
+		})
+	}
+}
@@ -2,13 +2,14 @@ package gom
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"database/sql/driver"
 	"errors"
 	"fmt"
 	"reflect"
-	"strconv"
 	"strings"
+	"time"
 )
 
 type SqlExecutor interface {
@@ -73,6 +74,24 @@ type ConDB struct {
 
 	rawSQL   string        //存放 Raw SQL
 	rawArgs  []interface{} //存放参数
+
+	ctx          context.Context
+	interceptors []Interceptor // 仅根节点使用
+	stmts        *stmtCache
+	replicas     *replicaPool
+	primary      bool // 强制走主库
+	cursor       *cursorState
+	chainErr     error            // 构建链时产生的错误
+	clock        func() time.Time // 仅根节点使用
+	skipHooks    bool
+	strictActor  bool         // 仅根节点使用
+	audit        *auditConfig // 仅根节点使用
+	model        reflect.Type // Model 传入的结构体类型
+	dry          *dryLog      // DryRun 会话记录的语句
+
+	tenant        interface{}
+	skipTenant    bool
+	tenantApplied bool // 已追加租户条件
 }
 
 var logger SqlLogger
@@ -99,6 +118,14 @@ func (m *ConDB) TraceOff() {
 
 func (m *ConDB) trace(query string, args ...interface{}) {
 	if logger != nil {
+		args = redactArgs(query, traceArgs(args))
+		if logDialect != "" && len(args) > 0 {
+			// 没有占位符的普通消息仍按原格式输出
+			if rendered := logDialect.Render(query, args...); rendered != query {
+				logger.Printf("%s%s", logPrefix, rendered)
+				return
+			}
+		}
 		var margs = argsToStr(args...)
 		logger.Printf("%s%s [%s]", logPrefix, query, margs)
 	}
@@ -111,6 +138,9 @@ func (m *ConDB) clone() *ConDB {
 		parent:  m,
 		tx:      nil,
 		builder: NewSQLBuilder(),
+		ctx:     m.ctx,
+		primary: m.primary,
+		tenant:  m.tenant,
 	}
 	return db
 }
@@ -123,10 +153,14 @@ func (m *ConDB) Model(class interface{}) *ConDB {
 		db := m.clone()
 
 		db.builder.From(getTable(class))
+		db.model = modelType(class)
+		registerModel(db.builder.table, db.model)
 		return db
 	} else {
 
 		m.builder.From(getTable(class))
+		m.model = modelType(class)
+		registerModel(m.builder.table, m.model)
 		return m
 	}
 
@@ -146,6 +180,12 @@ func (m *ConDB) Table(name string) *ConDB {
 
 func (m *ConDB) Where(query string, values ...interface{}) *ConDB {
 
+	// :name / @name 从 map 或结构体绑定
+	query, values, err := named(query, values)
+	if err != nil {
+		return m.fail(err)
+	}
+
 	if m.parent == nil {
 		db := m.clone()
 
@@ -161,6 +201,13 @@ func (m *ConDB) Where(query string, values ...interface{}) *ConDB {
 // Raw 执行原生 SQL
 func (m *ConDB) Raw(query string, args ...interface{}) *ConDB {
 	newDB := m.clone()
+	newDB.tx = m.tx
+	newDB.rawSQL = query
+	query, args, err := named(query, args)
+	if err != nil {
+		// Scan 执行时返回该错误
+		return newDB.fail(err)
+	}
 	newDB.rawSQL = query
 	newDB.rawArgs = args
 
@@ -174,7 +221,7 @@ func (m *ConDB) Scan(out interface{}) error {
 		return errors.New("no raw SQL provided, use Raw() first")
 	}
 
-	rows, err := m.Db.Query(m.rawSQL, m.rawArgs...)
+	rows, err := m.query(kindOf(m.rawSQL), "", m.rawSQL, m.rawArgs)
 	if err != nil {
 		return err
 	}
@@ -231,7 +278,7 @@ func (db *ConDB) Select(out interface{}, sql string, values ...interface{}) erro
 	if sql == "" {
 		return fmt.Errorf("sql is empty")
 	}
-	rows, err := db.Db.Query(sql, values...)
+	rows, err := db.query(kindOf(sql), "", sql, values)
 	if err != nil {
 
 		return err
@@ -398,13 +445,30 @@ func (m *ConDB) Count(args ...interface{}) int64 {
 		field = m.builder.fields
 	}
 
+	shards, err := m.routeShard()
+	if err == nil && len(shards) > 0 {
+		var count int64
+		count, err = m.fanOutCount(shards, field)
+		if err == nil {
+			return count
+		}
+	}
+	if err != nil {
+		m.Err = err
+		return 0
+	}
+
 	sqlStr := bytes.Buffer{}
 	sqlStr.WriteString("SELECT COUNT(")
 	sqlStr.WriteString(field)
 	sqlStr.WriteString(") FROM ")
 	sqlStr.WriteString(m.builder.table)
 
-	sql, argsList := m.builder.Build()
+	// 计数不需要排序和分页
+	b := *m.builder
+	b.orderBy = ""
+	b.limited = false
+	sql, argsList := b.Build()
 
 	// 提取 WHERE 子句（避免重复 FROM）
 	afterFrom := strings.SplitN(sql, "FROM "+m.builder.table, 2)
@@ -415,7 +479,10 @@ func (m *ConDB) Count(args ...interface{}) int64 {
 	m.trace(sqlStr.String(), argsList)
 
 	var count int64 = 0
-	err := m.Db.QueryRow(sqlStr.String(), argsList...).Scan(&count)
+	rows, err := m.query(StmtSelect, m.builder.table, sqlStr.String(), argsList)
+	if err == nil {
+		err = scanOne(rows, &count)
+	}
 	if err != nil {
 		m.Err = err
 		return 0
@@ -435,11 +502,18 @@ func (db *ConDB) Find(out interface{}) error {
 		table := getTable(out)
 		db.builder.From(table)
 	}
+	shards, err := db.routeShard()
+	if err != nil {
+		return err
+	}
+	if len(shards) > 0 {
+		return db.fanOutFind(shards, out)
+	}
 	sqlStr, args := db.builder.Build()
 
 	db.trace(sqlStr, args...)
 
-	rows, err := db.Db.Query(sqlStr, args...)
+	rows, err := db.query(StmtSelect, db.builder.table, sqlStr, args)
 	if err != nil {
 
 		return err
@@ -449,36 +523,45 @@ func (db *ConDB) Find(out interface{}) error {
 	return RowsToList(rows, out)
 }
 
+// FindAll loads page offset (0-based) of limit rows ordered by id desc.
+//
+// Deprecated: use SortKeys/After/CursorFind for keyset pagination.
 func (m *ConDB) FindAll(field string, limit, offset int64, out interface{}) *ConDB {
 	if m.parent == nil {
 		return nil
 	}
-	table := getTable(out)
+	if m.builder.table == "" {
+		m.builder.From(getTable(out))
+	}
+	// 与其他查询一样加上租户条件并路由分表
+	if m.Err = m.routeShardSingle(); m.Err != nil {
+		return m
+	}
 
 	if field == "" {
 		field = "*"
 	}
 
-	sqlStr := bytes.Buffer{}
-	sqlStr.WriteString("SELECT ")
-	sqlStr.WriteString(field)
-	sqlStr.WriteString(" FROM ")
-	sqlStr.WriteString(table)
-
-	offsetCalc := (offset + 1) * limit
-	sqlStr.WriteString(" WHERE id >= ((SELECT id FROM ")
-	sqlStr.WriteString(table)
-	sqlStr.WriteString(" ORDER BY id DESC LIMIT 1) - ")
-	sqlStr.WriteString(strconv.FormatInt(offsetCalc, 10))
-	sqlStr.WriteString(")")
+	// 先在主键索引上定位页首 id，再回表取数据；id 不连续时依然正确
+	head := *m.builder
+	head.fields = "id"
+	head.groupBy = ""
+	head.OrderBy("id DESC")
+	head.Limit(int32(offset*limit), 1)
+	headSQL, headArgs := head.Build()
 
-	sqlStr.WriteString(" ORDER BY id DESC")
-	sqlStr.WriteString(" LIMIT ")
-	sqlStr.WriteString(strconv.FormatInt(limit, 10))
+	page := *m.builder
+	page.clauses = append([]clause{}, m.builder.clauses...)
+	page.group()
+	page.Where("id <= ("+headSQL+")", headArgs...)
+	page.fields = field
+	page.OrderBy("id DESC")
+	page.Limit(0, int32(limit))
+	sqlStr, args := page.Build()
 
-	m.trace(sqlStr.String(), nil)
+	m.trace(sqlStr, args)
 
-	rows, err := m.Db.Query(sqlStr.String())
+	rows, err := m.query(StmtSelect, m.builder.table, sqlStr, args)
 	if err != nil {
 		m.Err = err
 		return m
@@ -498,13 +581,16 @@ func (db *ConDB) Query() (map[string]interface{}, error) {
 
 		return nil, errors.New("not found table")
 	}
+	if err := db.routeShardSingle(); err != nil {
+		return nil, err
+	}
 
 	sqlStr, args := db.builder.Build()
 	sqlStr += " LIMIT 1" // ✅ 限制只取一条
 
 	db.trace(sqlStr, args...)
 
-	rows, err := db.Db.Query(sqlStr, args...)
+	rows, err := db.query(StmtSelect, db.builder.table, sqlStr, args)
 	if err != nil {
 
 		return nil, err
@@ -523,12 +609,19 @@ func (db *ConDB) List() ([]map[string]interface{}, error) {
 
 		return nil, errors.New("not found table")
 	}
+	shards, err := db.routeShard()
+	if err != nil {
+		return nil, err
+	}
+	if len(shards) > 0 {
+		return db.fanOutList(shards)
+	}
 
 	sqlStr, params := db.builder.Build()
 
 	db.trace(sqlStr, params...)
 
-	rows, err := db.Db.Query(sqlStr, params...)
+	rows, err := db.query(StmtSelect, db.builder.table, sqlStr, params)
 	if err != nil {
 
 		return nil, err
@@ -541,6 +634,9 @@ func (db *ConDB) List() ([]map[string]interface{}, error) {
 func (db *ConDB) SelectInt(field string) int64 {
 
 	var out int64
+	if db.Err = db.routeShardSingle(); db.Err != nil {
+		return out
+	}
 	db.builder.Select(field)
 	db_sql, params := db.builder.Build()
 
@@ -548,12 +644,19 @@ func (db *ConDB) SelectInt(field string) int64 {
 
 	db.trace(db_sql, params...)
 
-	db.Err = db.Db.QueryRow(db_sql, params...).Scan(&out)
+	rows, err := db.query(StmtSelect, db.builder.table, db_sql, params)
+	if err == nil {
+		err = scanOne(rows, &out)
+	}
+	db.Err = err
 	return out
 }
 func (db *ConDB) SelectStr(field string) string {
 
 	var out string
+	if db.Err = db.routeShardSingle(); db.Err != nil {
+		return out
+	}
 	db.builder.Select(field)
 	db_sql, params := db.builder.Build()
 
@@ -561,7 +664,11 @@ func (db *ConDB) SelectStr(field string) string {
 
 	db.trace(db_sql, params...)
 
-	db.Err = db.Db.QueryRow(db_sql, params...).Scan(&out)
+	rows, err := db.query(StmtSelect, db.builder.table, db_sql, params)
+	if err == nil {
+		err = scanOne(rows, &out)
+	}
+	db.Err = err
 
 	return out
 }
@@ -574,6 +681,9 @@ func (m *ConDB) IsExit() (bool, error) {
 	if m.builder.table == "" {
 		return false, errors.New("no table defined")
 	}
+	if err := m.routeShardSingle(); err != nil {
+		return false, err
+	}
 
 	// 构建 WHERE 子句（提取 FROM 后）
 	sqlFull, args := m.builder.Build()
@@ -588,7 +698,10 @@ func (m *ConDB) IsExit() (bool, error) {
 	m.trace(query, args)
 
 	var one int
-	err := m.Db.QueryRow(query, args...).Scan(&one)
+	rows, err := m.query(StmtSelect, m.builder.table, query, args)
+	if err == nil {
+		err = scanOne(rows, &one)
+	}
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return false, nil
@@ -612,9 +725,12 @@ func (db *ConDB) FindById(out, id interface{}) error {
 	}
 
 	DB.builder.Where("id=?", id)
+	if err := DB.routeShardSingle(); err != nil {
+		return err
+	}
 	query, args := DB.builder.Build()
 	//DB.trace(sqlStr.String(), id)
-	rows, err := db.Db.Query(query, args...)
+	rows, err := DB.query(StmtSelect, DB.builder.table, query, args)
 	if err != nil {
 
 		return err
@@ -634,45 +750,65 @@ func (db *ConDB) Get(out interface{}) error {
 		db.builder.From(getTable(out))
 	}
 
+	t := reflect.TypeOf(out)
+	kind := t.Elem().Kind()
+
+	shards, err := db.routeShard()
+	if err != nil {
+		return err
+	}
+	if len(shards) > 0 {
+		if kind != reflect.Struct {
+			return ErrCrossShard
+		}
+		db.builder.Limit(0, 1)
+		list := reflect.New(reflect.SliceOf(t.Elem()))
+		if err := db.fanOutFind(shards, list.Interface()); err != nil {
+			return err
+		}
+		if list.Elem().Len() == 0 {
+			return sql.ErrNoRows
+		}
+		reflect.ValueOf(out).Elem().Set(list.Elem().Index(0))
+		return nil
+	}
+
 	query, args := db.builder.Build()
 	query += " LIMIT 1" // ✅ 限制只取一条
 
-	t := reflect.TypeOf(out)
-	kind := t.Elem().Kind()
+	rows, err := db.query(StmtSelect, db.builder.table, query, args)
+	if err != nil {
 
+		return err
+	}
 	if reflect.Struct == kind {
 
-		rows, err := db.Db.Query(query, args...)
-		if err != nil {
-
-			return err
-		}
 		defer rows.Close()
 
 		return RowToStruct(rows, out)
 
 	}
 
-	db.Err = db.Db.QueryRow(query, args...).Scan(out)
+	db.Err = scanOne(rows, out)
 	return db.Err
 
 }
 
 func (m *ConDB) QueryRow(query string, args ...interface{}) *sql.Row {
 	m.trace(query, args...)
-	return m.Db.QueryRow(query, args...)
+	return m.queryRow(kindOf(query), "", query, args)
 }
 
 func (m *ConDB) QueryRows(query string, args ...interface{}) (*sql.Rows, error) {
 	m.trace(query, args...)
-	return m.Db.Query(query, args...)
+	return m.query(kindOf(query), "", query, args)
 }
 
 func (db *ConDB) QueryMap(query string, args ...interface{}) (map[string]interface{}, error) {
 
 	if db.parent == nil {
 
-		rows, err := db.Db.Query(query, args...)
+		rows, err := db.query(kindOf(query), "", query, args)
 		if err != nil {
 			return nil, err
 		}
@@ -681,11 +817,14 @@ func (db *ConDB) QueryMap(query string, args ...interface{}) (map[string]interfa
 	}
 
 	db.builder.Where(query, args...)
+	if err := db.routeShardSingle(); err != nil {
+		return nil, err
+	}
 	sqlStr, params := db.builder.Build()
 	sqlStr += " LIMIT 1"
 
 	db.trace(sqlStr, params...)
-	rows, err := db.Db.Query(sqlStr, params...)
+	rows, err := db.query(StmtSelect, db.builder.table, sqlStr, params)
 	if err != nil {
 
 		return nil, err
@@ -698,7 +837,7 @@ func (db *ConDB) QueryMaps(query string, args ...interface{}) ([]map[string]inte
 
 	if db.parent == nil {
 
-		rows, err := db.Db.Query(query, args...)
+		rows, err := db.query(kindOf(query), "", query, args)
 		if err != nil {
 			return nil, err
 		}
@@ -707,10 +846,17 @@ func (db *ConDB) QueryMaps(query string, args ...interface{}) ([]map[string]inte
 	}
 
 	db.builder.Where(query, args...)
+	shards, err := db.routeShard()
+	if err != nil {
+		return nil, err
+	}
+	if len(shards) > 0 {
+		return db.fanOutList(shards)
+	}
 	sqlStr, params := db.builder.Build()
 
 	db.trace(sqlStr, params...)
-	rows, err := db.Db.Query(sqlStr, params...)
+	rows, err := db.query(StmtSelect, db.builder.table, sqlStr, params)
 	if err != nil {
 		return nil, err
 	}
@@ -16,7 +16,9 @@ type SQLBuilder struct {
 	clauses []clause
 	groupBy string
 	orderBy string
-	limit   string
+	limited bool
+	offset  int32
+	count   int32
 }
 
 func NewSQLBuilder() *SQLBuilder {
@@ -54,6 +56,41 @@ func (b *SQLBuilder) In(field string, values []interface{}) *SQLBuilder {
 	return b
 }
 
+// conditions 按顺序拼接条件：where / in 以 AND 连接，or 以 OR 连接
+func (b *SQLBuilder) conditions() (string, []interface{}) {
+	var buf strings.Builder
+	var args []interface{}
+	for i, c := range b.clauses {
+		if i > 0 {
+			if c.kind == "or" {
+				buf.WriteString(" OR ")
+			} else {
+				buf.WriteString(" AND ")
+			}
+		}
+		buf.WriteString(c.expr)
+		args = append(args, c.args...)
+	}
+	return buf.String(), args
+}
+
+// group 链上含 OR 时把已有条件合并为一个带括号的条件，
+// 之后追加的 AND 条件（租户、游标等）作用于整体而不是最后一个 OR 分支
+func (b *SQLBuilder) group() {
+	hasOr := false
+	for _, c := range b.clauses {
+		if c.kind == "or" {
+			hasOr = true
+			break
+		}
+	}
+	if !hasOr || len(b.clauses) < 2 {
+		return
+	}
+	cond, args := b.conditions()
+	b.clauses = []clause{{"where", "(" + cond + ")", args}}
+}
+
 func (b *SQLBuilder) GroupBy(group string) *SQLBuilder {
 	b.groupBy = group
 	return b
@@ -65,7 +102,9 @@ func (b *SQLBuilder) OrderBy(order string) *SQLBuilder {
 }
 
 func (b *SQLBuilder) Limit(offset, count int32) *SQLBuilder {
-	b.limit = fmt.Sprintf("LIMIT %d OFFSET %d", count, offset)
+	b.limited = true
+	b.offset = offset
+	b.count = count
 	return b
 }
 
@@ -82,35 +121,10 @@ func (b *SQLBuilder) Build() (string, []interface{}) {
 	buf.WriteString(" FROM ")
 	buf.WriteString(b.table)
 
-	first := true
-	for _, c := range b.clauses {
-		switch c.kind {
-		case "where":
-			if first {
-				buf.WriteString(" WHERE ")
-				first = false
-			} else {
-				buf.WriteString(" AND ")
-			}
-			buf.WriteString(c.expr)
-		case "or":
-			if first {
-				buf.WriteString(" WHERE ")
-				first = false
-			} else {
-				buf.WriteString(" OR ")
-			}
-			buf.WriteString(c.expr)
-		case "in":
-			if first {
-				buf.WriteString(" WHERE ")
-				first = false
-			} else {
-				buf.WriteString(" AND ")
-			}
-			buf.WriteString(c.expr)
-		}
-		args = append(args, c.args...)
+	if cond, condArgs := b.conditions(); cond != "" {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(cond)
+		args = append(args, condArgs...)
 	}
 
 	if b.groupBy != "" {
@@ -121,9 +135,8 @@ func (b *SQLBuilder) Build() (string, []interface{}) {
 		buf.WriteString(" ORDER BY ")
 		buf.WriteString(b.orderBy)
 	}
-	if b.limit != "" {
-		buf.WriteString(" ")
-		buf.WriteString(b.limit)
+	if b.limited {
+		buf.WriteString(fmt.Sprintf(" LIMIT %d OFFSET %d", b.count, b.offset))
 	}
 
 	return buf.String(), args
@@ -3,9 +3,11 @@ package gom
 import (
 	"bytes"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -20,30 +22,54 @@ func (m *ConDB) Insert(i interface{}) error {
 	} else {
 		db = m
 	}
+	i = db.dryCopy(i)
 
 	table := db.builder.table
 	if table == "" {
 		table = getTable(i)
 	}
+	if rv := reflect.ValueOf(i); rv.Kind() == reflect.Ptr && rv.Elem().Kind() == reflect.Struct {
+		registerModel(table, rv.Elem().Type())
+		if err := db.requireActor(table); err != nil {
+			return err
+		}
+		if err := db.fillTenant(table, rv.Elem()); err != nil {
+			return err
+		}
+	}
+	table, err := shardTableOf(table, i)
+	if err != nil {
+		return err
+	}
+
+	if rv := reflect.ValueOf(i); rv.Kind() == reflect.Ptr && rv.Elem().Kind() == reflect.Struct {
+		initVersion(rv.Elem())
+		if !db.skipHooks {
+			touchTimestamps(rv.Elem(), db.now(), true)
+			if err := db.fillActor(rv.Elem(), true); err != nil {
+				return err
+			}
+		}
+	}
 
-	fields, placeholders, args := buildInsertParts(i)
+	fields, placeholders, args, err := buildInsertParts(i, !db.skipHooks)
+	if err != nil {
+		return err
+	}
 
 	sqlStr := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(fields, ","), strings.Join(placeholders, ","))
 	m.trace(sqlStr, args)
 
-	var result sql.Result
-	var err error
-	if db.tx == nil {
-		result, err = db.Db.Exec(sqlStr, args...)
-	} else {
-		result, err = db.tx.Exec(sqlStr, args...)
-	}
+	result, err := db.exec(StmtInsert, table, sqlStr, args)
 	if err != nil {
 		db.Err = err
 		return err
 	}
 
 	insertId, err := result.LastInsertId()
+	if err == errDryRun {
+		return nil
+	}
 	if err == nil {
 		// 设置 struct 中的 Id 字段
 		rv := reflect.ValueOf(i).Elem()
@@ -88,7 +114,7 @@ func (m *ConDB) Insert(i interface{}) error {
 	return err
 }
 
-func buildInsertParts(i interface{}) ([]string, []string, []interface{}) {
+func buildInsertParts(i interface{}, hooks bool) ([]string, []string, []interface{}, error) {
 	val := reflect.ValueOf(i)
 	if val.Kind() == reflect.Ptr {
 		val = val.Elem()
@@ -96,7 +122,7 @@ func buildInsertParts(i interface{}) ([]string, []string, []interface{}) {
 	typ := val.Type()
 
 	// 调用 PreInsert() 方法（如果有）
-	if mth, ok := reflect.ValueOf(i).Type().MethodByName("PreInsert"); ok {
+	if mth, ok := reflect.ValueOf(i).Type().MethodByName("PreInsert"); ok && hooks {
 		mth.Func.Call([]reflect.Value{reflect.ValueOf(i)})
 	}
 
@@ -120,39 +146,119 @@ func buildInsertParts(i interface{}) ([]string, []string, []interface{}) {
 
 		if field.Anonymous && field.Type.Kind() == reflect.Struct {
 			// 嵌套匿名结构体支持
-			nestedFields, nestedPlaceholders, nestedArgs := buildInsertParts(val.Field(i).Addr().Interface())
+			nestedFields, nestedPlaceholders, nestedArgs, err := buildInsertParts(val.Field(i).Addr().Interface(), hooks)
+			if err != nil {
+				return nil, nil, nil, err
+			}
 			fields = append(fields, nestedFields...)
 			placeholders = append(placeholders, nestedPlaceholders...)
 			args = append(args, nestedArgs...)
 			continue
 		}
 
-		valStr := fmt.Sprintf("%v", value)
-		typeHint := field.Tag.Get("type")
+		value, ok, err := columnValue(field, value)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		fields = append(fields, tag)
+		placeholders = append(placeholders, "?")
+		args = append(args, value)
+	}
+	return fields, placeholders, args, nil
+}
+
+// columnValue 按 type 标签规范化写入值，返回 false 表示跳过该列
+func columnValue(field reflect.StructField, value interface{}) (interface{}, bool, error) {
+	// 注册的转换器优先
+	if v, ok, err := toDBConverter(value, field.Tag); ok {
+		return v, err == nil, err
+	}
+	if isJSONField(field.Tag) {
+		v, err := encodeJSON(value)
+		return v, err == nil, err
+	}
+
+	// driver.Valuer 与指针：nil 写入 NULL，其余取实际值
+	value, err := driverValue(value)
+	if err != nil {
+		return nil, false, err
+	}
+	if value == nil {
+		return nil, true, nil
+	}
+
+	typeHint := field.Tag.Get("type")
+	if t, ok := value.(time.Time); ok && (typeHint == "date" || typeHint == "datetime") {
+		if t.IsZero() {
+			return nil, false, nil
+		}
 		if typeHint == "date" {
-			valStr = FormatToDate(valStr)
-			if valStr == "" {
-				continue
-			}
-			value = valStr
-		} else if typeHint == "datetime" {
-			valStr = FormatToDatetime(valStr)
-			if valStr == "" {
-				continue
-			}
-			value = valStr
-		} else if typeHint == "decimal" {
+			return t.Format("2006-01-02"), true, nil
+		}
+		return t.Format("2006-01-02 15:04:05"), true, nil
+	}
 
-			if valStr == "" {
-				value = "0.00"
-			}		
+	valStr := parseString(value)
+	if typeHint == "date" {
+		valStr = FormatToDate(valStr)
+		if valStr == "" {
+			return nil, false, nil
+		}
+		value = valStr
+	} else if typeHint == "datetime" {
+		valStr = FormatToDatetime(valStr)
+		if valStr == "" {
+			return nil, false, nil
+		}
+		value = valStr
+	} else if typeHint == "decimal" {
+
+		if valStr == "" {
+			value = "0.00"
+		}
+	}
+	return value, true, nil
+}
+
+// buildUpdateParts 收集除主键外的所有列，用于按主键整行更新
+func buildUpdateParts(i interface{}) ([]string, []interface{}, error) {
+	val := reflect.Indirect(reflect.ValueOf(i))
+	typ := val.Type()
+
+	fields := []string{}
+	args := []interface{}{}
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			nestedFields, nestedArgs, err := buildUpdateParts(val.Field(i).Interface())
+			if err != nil {
+				return nil, nil, err
+			}
+			fields = append(fields, nestedFields...)
+			args = append(args, nestedArgs...)
+			continue
+		}
+		tag := field.Tag.Get("db")
+		if tag == "" || strings.ToLower(tag) == "id" {
+			continue
 		}
 
+		value, ok, err := columnValue(field, val.Field(i).Interface())
+		if err != nil {
+			return nil, nil, err
+		}
+		if !ok {
+			continue
+		}
 		fields = append(fields, tag)
-		placeholders = append(placeholders, "?")
 		args = append(args, value)
 	}
-	return fields, placeholders, args
+	return fields, args, nil
 }
 
 func (m *ConDB) Update(field string, values ...interface{}) error {
@@ -163,6 +269,13 @@ func (m *ConDB) Update(field string, values ...interface{}) error {
 	if m.builder.table == "" {
 		return errors.New("table not defined")
 	}
+	// SET 表达式由调用方给出，不自动填充 updatedBy，但严格模式照样检查
+	if err := m.requireActor(m.builder.table); err != nil {
+		return err
+	}
+	if err := m.routeShardSingle(); err != nil {
+		return err
+	}
 
 	sqlStr := bytes.Buffer{}
 	sqlStr.WriteString("UPDATE ")
@@ -180,11 +293,7 @@ func (m *ConDB) Update(field string, values ...interface{}) error {
 	m.trace(sqlStr.String(), params)
 
 	var err error
-	if m.tx == nil {
-		m.Result, err = m.Db.Exec(sqlStr.String(), params...)
-	} else {
-		m.Result, err = m.tx.Exec(sqlStr.String(), params...)
-	}
+	m.Result, err = m.exec(StmtUpdate, m.builder.table, sqlStr.String(), params)
 	if err != nil {
 		m.Err = err
 		return err
@@ -214,18 +323,147 @@ func (m *ConDB) UpdateMap(data map[string]interface{}) error {
 	if m.builder.table == "" {
 		return errors.New("table not defined")
 	}
+	table := m.builder.table
+	if err := m.requireActor(table); err != nil {
+		return err
+	}
+	if err := m.routeShardSingle(); err != nil {
+		return err
+	}
 	if len(data) == 0 {
 		return errors.New("empty update data")
 	}
+	if !m.skipHooks {
+		if m.model != nil {
+			data = touchMap(m.model, data, m.now())
+		}
+		data = m.actorMap(table, data)
+	}
 
-	// 构建 SET 子句
-	setParts := make([]string, 0, len(data))
+	// 列按名称排序，保证生成的 SQL 稳定
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	// 仅 Model 中声明 type:"json" 的列按 JSON 写入，其余值原样交给驱动
+	var fields map[string]fieldIndex
+	if m.model != nil {
+		fields = getFieldMap(m.model)
+	}
 	args := make([]interface{}, 0, len(data))
-	for key, value := range data {
-		setParts = append(setParts, fmt.Sprintf("%s = ?", key))
+	for _, key := range keys {
+		tag := fields[strings.ToLower(key)].Tag
+		value, ok, err := toDBConverter(data[key], tag)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			value = data[key]
+			if isJSONField(tag) {
+				if value, err = encodeJSON(value); err != nil {
+					return err
+				}
+			}
+		}
 		args = append(args, value)
 	}
 
+	_, err := m.updateColumns(keys, args)
+	return err
+}
+
+// Save updates every db-tagged column of i (a struct pointer) by its
+// primary key, db:"id". A field tagged gom:"version" is checked and
+// incremented; ErrStaleObject is returned when no row has that version.
+func (m *ConDB) Save(i interface{}) error {
+	db := m
+	if m.parent == nil {
+		db = m.clone()
+	}
+	i = db.dryCopy(i)
+	if db.builder.table == "" {
+		db.builder.From(getTable(i))
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(i))
+	table := db.builder.table
+	registerModel(table, rv.Type())
+	if err := db.requireActor(table); err != nil {
+		return err
+	}
+	idValue, pk, ok := findIDField(rv)
+	if !ok {
+		return errors.New(`missing field tag db:"id"`)
+	}
+	db.builder.Where(pk+" = ?", idValue)
+	db.whereShardKey(pk, rv)
+	if err := db.routeShardSingle(); err != nil {
+		return err
+	}
+
+	if !db.skipHooks {
+		if mth, ok := reflect.ValueOf(i).Type().MethodByName("PreUpdate"); ok {
+			mth.Func.Call([]reflect.Value{reflect.ValueOf(i)})
+		}
+		touchTimestamps(rv, db.now(), false)
+		if err := db.fillActor(rv, false); err != nil {
+			return err
+		}
+	}
+
+	fields, args, err := buildUpdateParts(i)
+	if err != nil {
+		return err
+	}
+
+	// 乐观锁：版本列按旧值匹配，并在 SQL 中自增
+	var raw []string
+	verCol, verIndex, versioned := versionField(rv.Type())
+	var verValue reflect.Value
+	if versioned {
+		verValue = rv.FieldByIndex(verIndex)
+		cur, ok := versionNumber(verValue)
+		if !ok {
+			return fmt.Errorf("gom: version field %s must be an integer", verCol)
+		}
+		for k := 0; k < len(fields); k++ {
+			if strings.EqualFold(fields[k], verCol) {
+				fields = append(fields[:k], fields[k+1:]...)
+				args = append(args[:k], args[k+1:]...)
+				k--
+			}
+		}
+		db.builder.Where(verCol+" = ?", cur)
+		raw = append(raw, fmt.Sprintf("%s = %s + 1", verCol, verCol))
+	}
+	if len(fields) == 0 && len(raw) == 0 {
+		return errors.New("empty update data")
+	}
+
+	return db.withAudit(table, "update", rv.Type(), pk, idValue, rv, func() error {
+		affected, err := db.updateColumns(fields, args, raw...)
+		if err != nil || !versioned {
+			return err
+		}
+		if affected == 0 {
+			return ErrStaleObject
+		}
+		if n, _ := versionNumber(verValue); verValue.CanSet() {
+			setVersion(verValue, n+1)
+		}
+		return nil
+	})
+}
+
+// updateColumns 执行 UPDATE table SET col = ?, ... 加上链上的 WHERE 条件
+func (m *ConDB) updateColumns(cols []string, args []interface{}, raw ...string) (int64, error) {
+	setParts := make([]string, 0, len(cols)+len(raw))
+	for _, key := range cols {
+		setParts = append(setParts, fmt.Sprintf("%s = ?", key))
+	}
+	setParts = append(setParts, raw...)
+
 	setClause := " SET " + strings.Join(setParts, ", ")
 
 	sqlStr := bytes.Buffer{}
@@ -244,23 +482,19 @@ func (m *ConDB) UpdateMap(data map[string]interface{}) error {
 	m.trace(sqlStr.String(), params)
 
 	var err error
-	if m.tx == nil {
-		m.Result, err = m.Db.Exec(sqlStr.String(), params...)
-	} else {
-		m.Result, err = m.tx.Exec(sqlStr.String(), params...)
-	}
+	m.Result, err = m.exec(StmtUpdate, m.builder.table, sqlStr.String(), params)
 	if err != nil {
 		m.Err = err
-		return err
+		return 0, err
 	}
 
 	affected, err := m.Result.RowsAffected()
 	if err != nil {
 		m.trace("RowsAffected error:", err)
-		return err
+		return 0, err
 	}
 	m.trace("RowsAffected num:", affected)
-	return nil
+	return affected, nil
 }
 
 func (m *ConDB) Exec(sql string, params ...interface{}) (sql.Result, error) {
@@ -273,15 +507,15 @@ func (m *ConDB) Exec(sql string, params ...interface{}) (sql.Result, error) {
 		db = m
 	}
 
-	db.trace(sql, params...)
-
-	if db.tx == nil {
-		db.Result, db.Err = db.Db.Exec(sql, params...)
+	sql, params, err := named(sql, params)
+	if err != nil {
+		db.Err = err
+		return nil, err
+	}
 
-	} else {
-		db.Result, db.Err = db.tx.Exec(sql, params...)
+	db.trace(sql, params...)
 
-	}
+	db.Result, db.Err = db.exec(kindOf(sql), "", sql, params)
 
 	return db.Result, db.Err
 
@@ -301,8 +535,33 @@ func (m *ConDB) Delete(i ...interface{}) error {
 		return errors.New(`missing field tag db:"id"`)
 	}
 
-	return m.Model(obj).Where(fmt.Sprintf("%s = ?", fieldName), idValue).delete()
+	db := m.Model(obj)
+	if err := db.requireActor(db.builder.table); err != nil {
+		return err
+	}
+	db.builder.Where(fmt.Sprintf("%s = ?", fieldName), idValue)
+	db.whereShardKey(fieldName, rv)
+	return db.withAudit(db.builder.table, "delete", rv.Type(), fieldName, idValue, reflect.Value{}, db.delete)
+}
+
+// deleteByID 按主键删除，开启审计时记录被删除的行；分表时主键须为分片键
+func (m *ConDB) deleteByID(t reflect.Type, pk string, id interface{}) error {
+	if err := m.requireActor(m.builder.table); err != nil {
+		return err
+	}
+	m.builder.Where(fmt.Sprintf("%s = ?", pk), id)
+	return m.withAudit(m.builder.table, "delete", t, pk, id, reflect.Value{}, m.delete)
+}
 
+// whereShardKey 分表且分片键不是主键时，从结构体取分片键加入条件
+func (m *ConDB) whereShardKey(pk string, rv reflect.Value) {
+	cfg := shardConfig(m.builder.table)
+	if cfg == nil || strings.EqualFold(cfg.Key, pk) {
+		return
+	}
+	if idx, ok := getFieldMap(rv.Type())[strings.ToLower(cfg.Key)]; ok {
+		m.builder.Where(cfg.Key+" = ?", rv.FieldByIndex(idx.Index).Interface())
+	}
 }
 
 func findIDField(v reflect.Value) (value interface{}, dbField string, found bool) {
@@ -339,6 +598,14 @@ func (m *ConDB) delete() error {
 		m.trace("no table specified")
 		return errors.New("table not defined")
 	}
+	// 经 withAudit 调用时表名已路由到分表，按逻辑表检查
+	table, _ := logicalTable(m.builder.table)
+	if err := m.requireActor(table); err != nil {
+		return err
+	}
+	if err := m.routeShardSingle(); err != nil {
+		return err
+	}
 
 	query, args := m.builder.Build()
 	// 从 WHERE 开始提取
@@ -354,11 +621,7 @@ func (m *ConDB) delete() error {
 	m.trace(deleteSQL, args)
 
 	var err error
-	if m.tx == nil {
-		m.Result, err = m.Db.Exec(deleteSQL, args...)
-	} else {
-		m.Result, err = m.tx.Exec(deleteSQL, args...)
-	}
+	m.Result, err = m.exec(StmtDelete, m.builder.table, deleteSQL, args)
 
 	if err != nil {
 		m.Err = err
@@ -375,6 +638,28 @@ func (m *ConDB) delete() error {
 	return nil
 }
 
+// driverValue 解析 driver.Valuer 和指针，返回可直接写入的值
+func driverValue(v interface{}) (interface{}, error) {
+	for v != nil {
+		if valuer, ok := v.(driver.Valuer); ok {
+			rv := reflect.ValueOf(v)
+			if rv.Kind() == reflect.Ptr && rv.IsNil() {
+				return nil, nil
+			}
+			return valuer.Value()
+		}
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Ptr {
+			return v, nil
+		}
+		if rv.IsNil() {
+			return nil, nil
+		}
+		v = rv.Elem().Interface()
+	}
+	return nil, nil
+}
+
 func FormatToDate(input string) string {
 	t, err := time.Parse("2006-01-02", input)
 	if err != nil {
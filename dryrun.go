@@ -0,0 +1,193 @@
+package gom
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// RecordedStatement is a statement captured in dry-run mode.
+type RecordedStatement struct {
+	Kind  StmtKind
+	Table string
+	Shard string
+	SQL   string
+	Args  []interface{}
+}
+
+type dryLog struct {
+	sync.Mutex
+	stmts []RecordedStatement
+}
+
+func (d *dryLog) record(next Handler) Handler {
+	return func(st *Statement) (*Outcome, error) {
+		d.Lock()
+		d.stmts = append(d.stmts, RecordedStatement{
+			Kind:  st.Kind,
+			Table: st.Table,
+			Shard: st.Shard,
+			SQL:   st.SQL,
+			Args:  append([]interface{}{}, st.Args...),
+		})
+		d.Unlock()
+		return next(st)
+	}
+}
+
+// DryRun returns a session that records statements instead of sending
+// them. It shares the configuration of m (clock, audit, tenants, actor
+// mode) but not interceptors, replicas or the statement cache, and its
+// connection never reaches the database: queries return no rows, writes
+// report no affected rows and transactions are no-ops. Insert and Save
+// work on a copy of their struct, so ids, timestamps, actors, versions and
+// tenants are not written back.
+func (m *ConDB) DryRun() *ConDB {
+	root := *m.root()
+	root.Db = dryDB()
+	root.tx = nil
+	root.builder = nil
+	root.interceptors = nil
+	root.stmts = nil
+	root.replicas = nil
+	root.chainErr = nil
+	root.Err = nil
+	root.dry = &dryLog{}
+	return &root
+}
+
+// Statements returns the statements recorded by the DryRun session the
+// chain belongs to.
+func (m *ConDB) Statements() []RecordedStatement {
+	d := m.root().dry
+	if d == nil {
+		return nil
+	}
+	d.Lock()
+	defer d.Unlock()
+	return append([]RecordedStatement{}, d.stmts...)
+}
+
+// ResetStatements clears the recorded statements.
+func (m *ConDB) ResetStatements() {
+	if d := m.root().dry; d != nil {
+		d.Lock()
+		d.stmts = nil
+		d.Unlock()
+	}
+}
+
+// ToSQL runs fn on a dry-run copy of the chain and returns the last
+// statement it generated, e.g.
+//
+//	sql, args, err := mdb.Model(User{}).Where("id = ?", 1).ToSQL(func(db *gom.ConDB) error {
+//		return db.UpdateMap(data)
+//	})
+//
+// The structs fn passes to Insert or Save are not modified.
+// Errors raised after a statement was generated (no rows, stale object)
+// are a consequence of not executing and are not reported.
+func (m *ConDB) ToSQL(fn func(db *ConDB) error) (string, []interface{}, error) {
+	session := m.DryRun()
+	var db *ConDB
+	if m.parent == nil {
+		db = session.clone()
+	} else {
+		db = m.fork()
+		db.parent = session
+		db.Db = session.Db
+		db.tx = nil
+	}
+	err := fn(db)
+	stmts := session.Statements()
+	if len(stmts) == 0 {
+		if err == nil {
+			err = errors.New("gom: no statement generated")
+		}
+		return "", nil, err
+	}
+	last := stmts[len(stmts)-1]
+	return last.SQL, last.Args, nil
+}
+
+// errDryRun 表示语句未真正执行，没有自增 id
+var errDryRun = errors.New("gom: dry run has no insert id")
+
+// dryCopy 在 DryRun 会话中返回结构体指针 i 的浅拷贝，钩子、时间戳、操作人、
+// 版本号和租户只写入拷贝，预览不会修改调用方的值
+func (m *ConDB) dryCopy(i interface{}) interface{} {
+	if m.root().dry == nil {
+		return i
+	}
+	rv := reflect.ValueOf(i)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return i
+	}
+	cp := reflect.New(rv.Elem().Type())
+	cp.Elem().Set(rv.Elem())
+	return cp.Interface()
+}
+
+// dryrun 驱动：不连接数据库，查询返回空结果
+var (
+	dryOnce sync.Once
+	dryConn *sql.DB
+)
+
+func dryDB() *sql.DB {
+	dryOnce.Do(func() {
+		sql.Register("gom-dryrun", dryDriver{})
+		dryConn, _ = sql.Open("gom-dryrun", "")
+	})
+	return dryConn
+}
+
+type dryDriver struct{}
+
+func (dryDriver) Open(string) (driver.Conn, error) { return dryConnection{}, nil }
+
+type dryConnection struct{}
+
+func (dryConnection) Prepare(query string) (driver.Stmt, error) { return dryStmt{}, nil }
+func (dryConnection) Close() error                              { return nil }
+func (dryConnection) Begin() (driver.Tx, error)                 { return dryTx{}, nil }
+
+// CheckNamedValue 接受任意参数类型，避免因驱动不支持而在记录前失败
+func (dryConnection) CheckNamedValue(*driver.NamedValue) error { return nil }
+
+func (dryConnection) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return dryResult{}, nil
+}
+
+func (dryConnection) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return dryRows{}, nil
+}
+
+type dryResult struct{}
+
+// LastInsertId 报错，Insert 据此不回写主键
+func (dryResult) LastInsertId() (int64, error) { return 0, errDryRun }
+func (dryResult) RowsAffected() (int64, error) { return 0, nil }
+
+type dryTx struct{}
+
+func (dryTx) Commit() error   { return nil }
+func (dryTx) Rollback() error { return nil }
+
+type dryStmt struct{}
+
+func (dryStmt) Close() error                               { return nil }
+func (dryStmt) NumInput() int                              { return -1 }
+func (dryStmt) Exec([]driver.Value) (driver.Result, error) { return dryResult{}, nil }
+func (dryStmt) Query([]driver.Value) (driver.Rows, error)  { return dryRows{}, nil }
+func (dryStmt) CheckNamedValue(*driver.NamedValue) error   { return nil }
+
+type dryRows struct{}
+
+func (dryRows) Columns() []string         { return nil }
+func (dryRows) Close() error              { return nil }
+func (dryRows) Next([]driver.Value) error { return io.EOF }
@@ -0,0 +1,90 @@
+package gom
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDryRunSendsNothing(t *testing.T) {
+	d := &testDriver{}
+	db := &ConDB{Db: d.open(t)}
+	calls := 0
+	db.Use(func(next Handler) Handler {
+		return func(st *Statement) (*Outcome, error) { calls++; return next(st) }
+	})
+	table := getTable(versionDoc{})
+
+	dry := db.DryRun()
+	ins := &versionDoc{Title: "x"}
+	if err := dry.Insert(ins); err != nil {
+		t.Fatal(err)
+	}
+	saved := &versionDoc{Id: 1, Title: "y", Version: 3}
+	// 预览不影响任何行，带版本号的 Save 因此报告 ErrStaleObject
+	if err := dry.Save(saved); err != ErrStaleObject {
+		t.Fatalf("Save: err = %v, want ErrStaleObject", err)
+	}
+	if err := dry.Table(table).Where("id = ?", 1).Delete(); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := d.statements(); len(s) != 0 {
+		t.Errorf("dry run reached the database: %q", s)
+	}
+	if calls != 0 {
+		t.Errorf("dry run ran the interceptors %d times", calls)
+	}
+	if *ins != (versionDoc{Title: "x"}) || *saved != (versionDoc{Id: 1, Title: "y", Version: 3}) {
+		t.Errorf("caller structs were modified: %+v %+v", *ins, *saved)
+	}
+
+	want := []RecordedStatement{
+		{Kind: StmtInsert, Table: table, SQL: "INSERT INTO " + table + " (title,version) VALUES (?,?)", Args: []interface{}{"x", 1}},
+		{Kind: StmtUpdate, Table: table, SQL: "UPDATE " + table + " SET title = ?, version = version + 1 WHERE id = ? AND version = ?", Args: []interface{}{"y", int64(1), int64(3)}},
+		{Kind: StmtDelete, Table: table, SQL: "DELETE FROM " + table + " WHERE id = ?", Args: []interface{}{1}},
+	}
+	if got := dry.Statements(); !reflect.DeepEqual(got, want) {
+		t.Errorf("statements = %+v\nwant %+v", got, want)
+	}
+	dry.ResetStatements()
+	if n := len(dry.Statements()); n != 0 {
+		t.Errorf("%d statements left after ResetStatements", n)
+	}
+}
+
+func TestToSQL(t *testing.T) {
+	d := &testDriver{}
+	db := &ConDB{Db: d.open(t)}
+	table := getTable(versionDoc{})
+
+	chain := db.Model(versionDoc{}).Where("id = ?", 1)
+	sql, args, err := chain.ToSQL(func(db *ConDB) error {
+		var out []versionDoc
+		if err := db.Find(&out); err != nil {
+			return err
+		}
+		return db.UpdateMap(map[string]interface{}{"title": "x"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sql != "UPDATE "+table+" SET title = ? WHERE id = ?" || !reflect.DeepEqual(args, []interface{}{"x", 1}) {
+		t.Errorf("ToSQL = %q %v, want the last statement", sql, args)
+	}
+	if s := d.statements(); len(s) != 0 {
+		t.Errorf("ToSQL reached the database: %q", s)
+	}
+
+	// 链本身不受影响，仍可正常执行
+	var out []versionDoc
+	if err := chain.Find(&out); err != nil {
+		t.Fatal(err)
+	}
+	if s := d.statements(); !reflect.DeepEqual(s, []string{"SELECT * FROM " + table + " WHERE id = ?"}) {
+		t.Errorf("statements after ToSQL = %q", s)
+	}
+
+	if _, _, err := db.ToSQL(func(*ConDB) error { return nil }); err == nil {
+		t.Error("ToSQL without a statement returned no error")
+	}
+}
@@ -0,0 +1,177 @@
+package gom
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testDriver 是测试用的内存驱动：查询按 SQL 中的表名返回预置行，
+// 写入返回 affected 行，并按顺序记录语句、参数及事务边界
+type testDriver struct {
+	cols     []string
+	tables   map[string][][]driver.Value
+	affected func(query string) int64 // 为 nil 时每条写入影响 1 行
+
+	mu       sync.Mutex
+	seen     []string
+	args     [][]interface{}
+	prepared int   // 已准备的语句数
+	closed   int   // 已关闭的语句数
+	lastID   int64 // 每次写入递增，作为 LastInsertId
+}
+
+var errStmtClosed = errors.New("test driver: statement used after close")
+
+func (d *testDriver) Open(string) (driver.Conn, error)             { return testConn{d}, nil }
+func (d *testDriver) Connect(context.Context) (driver.Conn, error) { return testConn{d}, nil }
+func (d *testDriver) Driver() driver.Driver                        { return d }
+
+func (d *testDriver) open(t *testing.T) *sql.DB {
+	db := sql.OpenDB(d)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func (d *testDriver) statements() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string{}, d.seen...)
+}
+
+func (d *testDriver) record(query string, args []driver.NamedValue) {
+	vals := make([]interface{}, len(args))
+	for i, a := range args {
+		vals[i] = a.Value
+	}
+	d.mu.Lock()
+	d.seen = append(d.seen, query)
+	d.args = append(d.args, vals)
+	d.mu.Unlock()
+}
+
+func (d *testDriver) query(query string, args []driver.NamedValue) (driver.Rows, error) {
+	d.record(query, args)
+	for table, rows := range d.tables {
+		if !strings.Contains(query, "FROM "+table+" ") && !strings.HasSuffix(query, "FROM "+table) {
+			continue
+		}
+		if strings.Contains(query, "COUNT(") {
+			return &testRows{cols: []string{"n"}, data: [][]driver.Value{{int64(len(rows))}}}, nil
+		}
+		return &testRows{cols: d.cols, data: rows}, nil
+	}
+	return &testRows{cols: d.cols}, nil
+}
+
+func (d *testDriver) exec(query string, args []driver.NamedValue) (driver.Result, error) {
+	d.record(query, args)
+	n := int64(1)
+	if d.affected != nil {
+		n = d.affected(query)
+	}
+	d.mu.Lock()
+	d.lastID++
+	id := d.lastID
+	d.mu.Unlock()
+	return testResult{id, n}, nil
+}
+
+type testResult struct{ id, affected int64 }
+
+func (r testResult) LastInsertId() (int64, error) { return r.id, nil }
+func (r testResult) RowsAffected() (int64, error) { return r.affected, nil }
+
+type testConn struct{ d *testDriver }
+
+func (c testConn) Prepare(query string) (driver.Stmt, error) {
+	c.d.mu.Lock()
+	c.d.prepared++
+	c.d.mu.Unlock()
+	return &testStmt{d: c.d, query: query}, nil
+}
+
+func (testConn) Close() error                             { return nil }
+func (testConn) CheckNamedValue(*driver.NamedValue) error { return nil }
+
+func (c testConn) Begin() (driver.Tx, error) {
+	c.d.record("BEGIN", nil)
+	return testTx{c.d}, nil
+}
+
+func (c testConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.d.query(query, args)
+}
+
+func (c testConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.d.exec(query, args)
+}
+
+type testTx struct{ d *testDriver }
+
+func (tx testTx) Commit() error   { tx.d.record("COMMIT", nil); return nil }
+func (tx testTx) Rollback() error { tx.d.record("ROLLBACK", nil); return nil }
+
+type testStmt struct {
+	d      *testDriver
+	query  string
+	closed bool
+}
+
+func (s *testStmt) Close() error {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		s.d.closed++
+	}
+	return nil
+}
+
+func (s *testStmt) NumInput() int                            { return -1 }
+func (s *testStmt) CheckNamedValue(*driver.NamedValue) error { return nil }
+
+func (s *testStmt) isClosed() bool {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	return s.closed
+}
+
+func (s *testStmt) QueryContext(_ context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if s.isClosed() {
+		return nil, errStmtClosed
+	}
+	return s.d.query(s.query, args)
+}
+
+func (s *testStmt) ExecContext(_ context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if s.isClosed() {
+		return nil, errStmtClosed
+	}
+	return s.d.exec(s.query, args)
+}
+
+func (s *testStmt) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (s *testStmt) Query([]driver.Value) (driver.Rows, error)  { return nil, driver.ErrSkip }
+
+type testRows struct {
+	cols []string
+	data [][]driver.Value
+}
+
+func (r *testRows) Columns() []string { return r.cols }
+func (r *testRows) Close() error      { return nil }
+
+func (r *testRows) Next(dest []driver.Value) error {
+	if len(r.data) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.data[0])
+	r.data = r.data[1:]
+	return nil
+}
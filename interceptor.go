@@ -0,0 +1,276 @@
+package gom
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+)
+
+// Executor is the part of *sql.DB, *sql.Tx and *sql.Conn that gom needs to
+// run statements.
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+var (
+	_ Executor = &sql.DB{}
+	_ Executor = &sql.Tx{}
+	_ Executor = &sql.Conn{}
+)
+
+// ErrNoOutcome is returned when an interceptor reports success without
+// the rows, row or result the statement needs.
+var ErrNoOutcome = errors.New("gom: interceptor returned no outcome")
+
+// StmtKind is the verb of a statement.
+type StmtKind string
+
+const (
+	StmtSelect StmtKind = "select"
+	StmtInsert StmtKind = "insert"
+	StmtUpdate StmtKind = "update"
+	StmtDelete StmtKind = "delete"
+	StmtOther  StmtKind = "other"
+)
+
+// StmtMode tells the executor which database/sql call a statement needs.
+type StmtMode int
+
+const (
+	ModeQuery    StmtMode = iota // *sql.Rows
+	ModeQueryRow                 // *sql.Row
+	ModeExec                     // sql.Result
+)
+
+// Statement describes one SQL call on its way to the database.
+// Interceptors may inspect or rewrite any field before calling next.
+// Table is the logical table; for a sharded table the physical table the
+// statement runs on is in Shard.
+type Statement struct {
+	Ctx      context.Context
+	Kind     StmtKind
+	Mode     StmtMode
+	Table    string
+	Shard    string
+	SQL      string
+	Args     []interface{}
+	Executor Executor
+}
+
+// Outcome holds what a statement produced; only the field matching the
+// statement's Mode is set.
+type Outcome struct {
+	Rows   *sql.Rows
+	Row    *sql.Row
+	Result sql.Result
+}
+
+type Handler func(st *Statement) (*Outcome, error)
+
+type Interceptor func(next Handler) Handler
+
+// Use registers interceptors on the root ConDB. They wrap every statement
+// sent by any chain derived from it, the first registered being the outermost.
+func (m *ConDB) Use(interceptors ...Interceptor) *ConDB {
+	root := m.root()
+	root.interceptors = append(root.interceptors, interceptors...)
+	return m
+}
+
+// WithContext binds ctx to the chain; statements run by it receive ctx.
+func (m *ConDB) WithContext(ctx context.Context) *ConDB {
+	if m.parent == nil {
+		db := m.clone()
+		db.ctx = ctx
+		return db
+	} else {
+		m.ctx = ctx
+		return m
+	}
+}
+
+// fail 记录构建链时的错误，该链执行语句时返回此错误
+func (m *ConDB) fail(err error) *ConDB {
+	db := m
+	if m.parent == nil {
+		db = m.clone()
+	}
+	if db.chainErr == nil {
+		db.chainErr = err
+	}
+	db.Err = err
+	return db
+}
+
+func (m *ConDB) root() *ConDB {
+	for m.parent != nil {
+		m = m.parent
+	}
+	return m
+}
+
+func (m *ConDB) context() context.Context {
+	if m.ctx != nil {
+		return m.ctx
+	}
+	return context.Background()
+}
+
+func (m *ConDB) executor(kind StmtKind, mode StmtMode) Executor {
+	if m.tx != nil {
+		return m.tx
+	}
+	if kind == StmtSelect && mode != ModeExec && !m.primary {
+		if pool := m.root().replicas; pool != nil {
+			return pool.pick()
+		}
+	}
+	return m.Db
+}
+
+func (m *ConDB) run(st *Statement) (*Outcome, error) {
+	if m.chainErr != nil {
+		return nil, m.chainErr
+	}
+	root := m.root()
+	h := Handler(execute)
+	if root.stmts != nil {
+		h = root.executeCached
+	}
+	if root.replicas != nil {
+		h = root.replicas.measure(h)
+	}
+	if root.dry != nil {
+		h = root.dry.record(h)
+	}
+	for i := len(root.interceptors) - 1; i >= 0; i-- {
+		h = root.interceptors[i](h)
+	}
+	return h(st)
+}
+
+func execute(st *Statement) (*Outcome, error) {
+	switch st.Mode {
+	case ModeQuery:
+		rows, err := st.Executor.QueryContext(st.Ctx, st.SQL, st.Args...)
+		return &Outcome{Rows: rows}, err
+	case ModeQueryRow:
+		return &Outcome{Row: st.Executor.QueryRowContext(st.Ctx, st.SQL, st.Args...)}, nil
+	default:
+		res, err := st.Executor.ExecContext(st.Ctx, st.SQL, st.Args...)
+		return &Outcome{Result: res}, err
+	}
+}
+
+func (m *ConDB) statement(kind StmtKind, mode StmtMode, table, query string, args []interface{}) *Statement {
+	table, shard := logicalTable(table)
+	return &Statement{
+		Ctx:      m.context(),
+		Kind:     kind,
+		Mode:     mode,
+		Table:    table,
+		Shard:    shard,
+		SQL:      query,
+		Args:     args,
+		Executor: m.executor(kind, mode),
+	}
+}
+
+func (m *ConDB) query(kind StmtKind, table, query string, args []interface{}) (*sql.Rows, error) {
+	out, err := m.run(m.statement(kind, ModeQuery, table, query, args))
+	if err != nil {
+		if out != nil && out.Rows != nil {
+			out.Rows.Close()
+		}
+		return nil, err
+	}
+	if out == nil || out.Rows == nil {
+		return nil, ErrNoOutcome
+	}
+	return out.Rows, nil
+}
+
+// queryRow 出错时返回一个 Scan 即报该错误的 *sql.Row，不会返回 nil
+func (m *ConDB) queryRow(kind StmtKind, table, query string, args []interface{}) *sql.Row {
+	out, err := m.run(m.statement(kind, ModeQueryRow, table, query, args))
+	if err == nil && (out == nil || out.Row == nil) {
+		err = ErrNoOutcome
+	}
+	if err != nil {
+		return errRow(err)
+	}
+	return out.Row
+}
+
+// errRow 返回 Scan 时报 err 的 *sql.Row。database/sql 不允许在包外构造带错误的 Row，
+// 因此经一个只会返回该错误的连接查询；Row 不持有连接，可立即关闭
+func errRow(err error) *sql.Row {
+	db := sql.OpenDB(errConnector{err})
+	defer db.Close()
+	return db.QueryRowContext(context.Background(), "")
+}
+
+type errConnector struct{ err error }
+
+func (c errConnector) Connect(context.Context) (driver.Conn, error) { return errConn(c), nil }
+func (c errConnector) Driver() driver.Driver                        { return c }
+func (c errConnector) Open(string) (driver.Conn, error)             { return errConn(c), nil }
+
+type errConn struct{ err error }
+
+func (c errConn) Prepare(string) (driver.Stmt, error) { return nil, c.err }
+func (c errConn) Close() error                        { return nil }
+func (c errConn) Begin() (driver.Tx, error)           { return nil, c.err }
+
+func (c errConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return nil, c.err
+}
+
+func (m *ConDB) exec(kind StmtKind, table, query string, args []interface{}) (sql.Result, error) {
+	out, err := m.run(m.statement(kind, ModeExec, table, query, args))
+	if err != nil {
+		return nil, err
+	}
+	if out == nil || out.Result == nil {
+		return nil, ErrNoOutcome
+	}
+	return out.Result, nil
+}
+
+// scanOne 读取单行到 dest，并关闭 rows
+func scanOne(rows *sql.Rows, dest ...interface{}) error {
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// kindOf 根据首个关键字推断原生 SQL 的类型
+func kindOf(query string) StmtKind {
+	s := strings.TrimSpace(query)
+	if i := strings.IndexAny(s, " \t\r\n("); i > 0 {
+		s = s[:i]
+	}
+	switch strings.ToLower(s) {
+	case "select", "with", "show":
+		return StmtSelect
+	case "insert", "replace":
+		return StmtInsert
+	case "update":
+		return StmtUpdate
+	case "delete":
+		return StmtDelete
+	}
+	return StmtOther
+}
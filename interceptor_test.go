@@ -0,0 +1,78 @@
+package gom
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestErrRow(t *testing.T) {
+	boom := errors.New("boom")
+	var n int
+	if err := errRow(boom).Scan(&n); err != boom {
+		t.Errorf("Scan = %v, want %v", err, boom)
+	}
+}
+
+func TestInterceptorFailures(t *testing.T) {
+	boom := errors.New("boom")
+	tests := []struct {
+		name string
+		h    Handler
+		want error
+	}{
+		{"error", func(*Statement) (*Outcome, error) { return nil, boom }, boom},
+		{"nil outcome", func(*Statement) (*Outcome, error) { return nil, nil }, ErrNoOutcome},
+		{"empty outcome", func(*Statement) (*Outcome, error) { return &Outcome{}, nil }, ErrNoOutcome},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &testDriver{}
+			db := &ConDB{Db: d.open(t)}
+			db.Use(func(Handler) Handler { return tt.h })
+
+			var n int
+			if err := db.QueryRow("SELECT 1").Scan(&n); err != tt.want {
+				t.Errorf("QueryRow: err = %v, want %v", err, tt.want)
+			}
+			if _, err := db.Table("tb_x").List(); err != tt.want {
+				t.Errorf("List: err = %v, want %v", err, tt.want)
+			}
+			if err := db.Table("tb_x").Where("id = ?", 1).UpdateMap(map[string]interface{}{"a": 1}); err != tt.want {
+				t.Errorf("UpdateMap: err = %v, want %v", err, tt.want)
+			}
+			if got := d.statements(); len(got) != 0 {
+				t.Errorf("statements reached the driver: %q", got)
+			}
+		})
+	}
+}
+
+func TestInterceptorOrder(t *testing.T) {
+	d := &testDriver{}
+	db := &ConDB{Db: d.open(t)}
+	var order []string
+	mark := func(name string) Interceptor {
+		return func(next Handler) Handler {
+			return func(st *Statement) (*Outcome, error) {
+				order = append(order, name)
+				return next(st)
+			}
+		}
+	}
+	db.Use(mark("a"), mark("b"))
+	db.Use(func(next Handler) Handler {
+		return func(st *Statement) (*Outcome, error) {
+			st.SQL += " /* c */"
+			return next(st)
+		}
+	})
+	if err := db.Table("tb_x").Where("id = ?", 1).Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if len(order) != 2 || order[0] != "a" || order[1] != "b" {
+		t.Errorf("order = %v, want a then b", order)
+	}
+	if got := d.statements(); len(got) != 1 || got[0] != "DELETE FROM tb_x WHERE id = ? /* c */" {
+		t.Errorf("statements = %q", got)
+	}
+}
@@ -0,0 +1,141 @@
+package gom
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrStop may be returned from an Each or FindInBatches callback to end the
+// walk early without reporting an error.
+var ErrStop = errors.New("gom: stop iteration")
+
+// RowIterator walks a result set one row at a time instead of loading it
+// into a slice. It must be closed; Close is safe to call more than once.
+type RowIterator struct {
+	rows     *sql.Rows
+	cols     []string
+	fieldMap map[string]fieldIndex
+	elem     reflect.Value
+	err      error
+}
+
+// Rows runs the chain's query and returns an iterator whose Scan fills
+// elem, a pointer to a struct that also names the table when none is set.
+// Inside a transaction no other statement may run on the tx until the
+// iterator is closed.
+func (m *ConDB) Rows(elem interface{}) (*RowIterator, error) {
+	if m.parent == nil {
+		return nil, errors.New("Lack of ConDB objects")
+	}
+	rv := reflect.ValueOf(elem)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("gom: Rows needs a pointer to struct")
+	}
+	if m.builder.table == "" {
+		m.builder.From(getTable(elem))
+	}
+	if err := m.routeShardSingle(); err != nil {
+		return nil, err
+	}
+
+	sqlStr, args := m.builder.Build()
+	m.trace(sqlStr, args...)
+
+	rows, err := m.query(StmtSelect, m.builder.table, sqlStr, args)
+	if err != nil {
+		return nil, err
+	}
+	cols, err := rows.Columns()
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+	return &RowIterator{
+		rows:     rows,
+		cols:     cols,
+		fieldMap: getFieldMap(rv.Elem().Type()),
+		elem:     rv.Elem(),
+	}, nil
+}
+
+// Next advances to the next row. It returns false at the end of the result
+// set or on error, closing the cursor in both cases.
+func (it *RowIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.rows.Next() {
+		return true
+	}
+	it.err = it.rows.Err()
+	it.rows.Close()
+	return false
+}
+
+// Scan maps the current row into the element bound by Rows.
+func (it *RowIterator) Scan() error {
+	it.elem.Set(reflect.Zero(it.elem.Type()))
+	if err := scanStruct(it.rows, it.cols, it.fieldMap, it.elem); err != nil {
+		it.err = err
+		return err
+	}
+	return nil
+}
+
+func (it *RowIterator) Err() error {
+	return it.err
+}
+
+func (it *RowIterator) Close() error {
+	return it.rows.Close()
+}
+
+// Each streams the chain's rows into fn, which must be a func(T) error or
+// func(*T) error for a struct T. Returning ErrStop ends the walk quietly;
+// any other error ends it and is returned.
+func (m *ConDB) Each(fn interface{}) error {
+	fv := reflect.ValueOf(fn)
+	if !fv.IsValid() || fv.Kind() == reflect.Func && fv.IsNil() {
+		return errors.New("gom: Each needs a non-nil func(T) error")
+	}
+	ft := fv.Type()
+	errType := reflect.TypeOf((*error)(nil)).Elem()
+	if ft.Kind() != reflect.Func || ft.NumIn() != 1 || ft.NumOut() != 1 || ft.Out(0) != errType {
+		return fmt.Errorf("gom: Each needs func(T) error, got %s", ft)
+	}
+	argType := ft.In(0)
+	byPtr := argType.Kind() == reflect.Ptr
+	elemType := argType
+	if byPtr {
+		elemType = argType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return fmt.Errorf("gom: Each needs a struct argument, got %s", argType)
+	}
+
+	elem := reflect.New(elemType)
+	it, err := m.Rows(elem.Interface())
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+
+	for it.Next() {
+		if err := it.Scan(); err != nil {
+			return err
+		}
+		arg := elem.Elem()
+		if byPtr {
+			arg = elem
+		}
+		if out := fv.Call([]reflect.Value{arg})[0]; !out.IsNil() {
+			if err := out.Interface().(error); err != ErrStop {
+				return err
+			}
+			return nil
+		}
+	}
+	return it.Err()
+}
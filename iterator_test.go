@@ -0,0 +1,97 @@
+package gom
+
+import (
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type iterItem struct {
+	Id   int64  `db:"id"`
+	Name string `db:"name"`
+}
+
+func iterDB(t *testing.T) *ConDB {
+	d := &testDriver{
+		cols:   []string{"id", "name"},
+		tables: map[string][][]driver.Value{"tb_iter": {{int64(1), "a"}, {int64(2), "b"}, {int64(3), "c"}}},
+	}
+	return &ConDB{Db: d.open(t)}
+}
+
+func TestEach(t *testing.T) {
+	boom := errors.New("boom")
+	var nilFn func(iterItem) error
+	tests := []struct {
+		name    string
+		fn      func(seen *[]int64) interface{}
+		want    []int64
+		wantErr error
+		invalid bool
+	}{
+		{"value", func(seen *[]int64) interface{} {
+			return func(it iterItem) error { *seen = append(*seen, it.Id); return nil }
+		}, []int64{1, 2, 3}, nil, false},
+		{"pointer", func(seen *[]int64) interface{} {
+			return func(it *iterItem) error { *seen = append(*seen, it.Id); return nil }
+		}, []int64{1, 2, 3}, nil, false},
+		{"stop", func(seen *[]int64) interface{} {
+			return func(it iterItem) error {
+				*seen = append(*seen, it.Id)
+				if it.Id == 2 {
+					return ErrStop
+				}
+				return nil
+			}
+		}, []int64{1, 2}, nil, false},
+		{"error", func(seen *[]int64) interface{} {
+			return func(it iterItem) error { *seen = append(*seen, it.Id); return boom }
+		}, []int64{1}, boom, false},
+		{"nil", func(*[]int64) interface{} { return nil }, nil, nil, true},
+		{"nil func", func(*[]int64) interface{} { return nilFn }, nil, nil, true},
+		{"not a func", func(*[]int64) interface{} { return 1 }, nil, nil, true},
+		{"wrong signature", func(*[]int64) interface{} { return func(iterItem) {} }, nil, nil, true},
+		{"not a struct", func(*[]int64) interface{} { return func(int) error { return nil } }, nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen []int64
+			err := iterDB(t).Table("tb_iter").Each(tt.fn(&seen))
+			if tt.invalid {
+				if err == nil {
+					t.Error("Each accepted an invalid callback")
+				}
+				return
+			}
+			if err != tt.wantErr {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(seen, tt.want) {
+				t.Errorf("seen = %v, want %v", seen, tt.want)
+			}
+		})
+	}
+}
+
+func TestRows(t *testing.T) {
+	var item iterItem
+	it, err := iterDB(t).Table("tb_iter").Rows(&item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer it.Close()
+	var names []string
+	for it.Next() {
+		if err := it.Scan(); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, item.Name)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"a", "b", "c"}) {
+		t.Errorf("names = %v", names)
+	}
+}
@@ -0,0 +1,96 @@
+package gom
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// isJSONField 判断字段是否声明了 type:"json"
+func isJSONField(tag reflect.StructTag) bool {
+	return tag.Get("type") == "json"
+}
+
+// decodeJSON 将 JSON 列反序列化为 targetType
+func decodeJSON(raw interface{}, targetType reflect.Type) (reflect.Value, error) {
+	var b []byte
+	switch v := raw.(type) {
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return reflect.Zero(targetType), fmt.Errorf("gom: cannot decode %T as JSON", raw)
+	}
+	if len(b) == 0 {
+		return reflect.Zero(targetType), nil
+	}
+	ptr := reflect.New(targetType)
+	if err := json.Unmarshal(b, ptr.Interface()); err != nil {
+		return reflect.Zero(targetType), err
+	}
+	return ptr.Elem(), nil
+}
+
+// encodeJSON 序列化 JSON 列，nil 的指针、map、切片写入 NULL
+func encodeJSON(v interface{}) (interface{}, error) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Invalid:
+		return nil, nil
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		if rv.IsNil() {
+			return nil, nil
+		}
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// jsonPath 规范化 JSON 路径："a.b" => "$.a.b"
+func jsonPath(path string) string {
+	if strings.HasPrefix(path, "$") {
+		return path
+	}
+	if strings.HasPrefix(path, "[") {
+		return "$" + path
+	}
+	return "$." + path
+}
+
+var jsonOps = map[string]bool{
+	"=": true, "!=": true, "<>": true, ">": true, ">=": true, "<": true, "<=": true,
+	"LIKE": true, "NOT LIKE": true,
+}
+
+// WhereJSON adds "JSON_UNQUOTE(JSON_EXTRACT(column, path)) op ?", the
+// same as column->>'path'. path may omit the leading "$.".
+func (m *ConDB) WhereJSON(column, path, op string, value interface{}) *ConDB {
+	op = strings.ToUpper(strings.TrimSpace(op))
+	if !jsonOps[op] {
+		return m.fail(fmt.Errorf("gom: unsupported JSON operator %q", op))
+	}
+	return m.Where(fmt.Sprintf("JSON_UNQUOTE(JSON_EXTRACT(%s, ?)) %s ?", column, op), jsonPath(path), value)
+}
+
+// JSONContains adds "JSON_CONTAINS(column, ?[, path])", value being
+// marshaled to JSON.
+func (m *ConDB) JSONContains(column string, value interface{}, path ...string) *ConDB {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return m.fail(err)
+	}
+	if len(path) > 0 {
+		return m.Where(fmt.Sprintf("JSON_CONTAINS(%s, ?, ?)", column), string(b), jsonPath(path[0]))
+	}
+	return m.Where(fmt.Sprintf("JSON_CONTAINS(%s, ?)", column), string(b))
+}
+
+// JSONHasPath adds "JSON_CONTAINS_PATH(column, 'one', path)".
+func (m *ConDB) JSONHasPath(column, path string) *ConDB {
+	return m.Where(fmt.Sprintf("JSON_CONTAINS_PATH(%s, 'one', ?)", column), jsonPath(path))
+}
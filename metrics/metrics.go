@@ -0,0 +1,194 @@
+// Package metrics collects per-statement counters and latency histograms
+// from gom's interceptor chain and renders them in the Prometheus text
+// exposition format, without depending on the Prometheus client.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gkyh/gom"
+)
+
+// DefaultBuckets are latency histogram upper bounds in seconds.
+var DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+
+type key struct {
+	table string
+	kind  gom.StmtKind
+}
+
+type series struct {
+	count   uint64
+	errors  uint64
+	sum     float64
+	buckets []uint64 // 非累计，输出时再累加
+}
+
+// Sample is a point-in-time copy of one table/kind series.
+type Sample struct {
+	Table    string
+	Kind     gom.StmtKind
+	Count    uint64
+	Errors   uint64
+	Sum      time.Duration
+	Buckets  []float64
+	Observed []uint64 // 累计计数，与 Buckets 一一对应
+}
+
+type Registry struct {
+	namespace string
+	buckets   []float64
+
+	mu     sync.Mutex
+	series map[key]*series
+}
+
+// NewRegistry creates a registry whose metric names start with namespace
+// ("gom" if empty). With no buckets, DefaultBuckets is used.
+func NewRegistry(namespace string, buckets ...float64) *Registry {
+	if namespace == "" {
+		namespace = "gom"
+	}
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	b := append([]float64{}, buckets...)
+	sort.Float64s(b)
+	return &Registry{namespace: namespace, buckets: b, series: make(map[key]*series)}
+}
+
+// Interceptor returns the gom interceptor feeding this registry.
+func (r *Registry) Interceptor() gom.Interceptor {
+	return func(next gom.Handler) gom.Handler {
+		return func(st *gom.Statement) (*gom.Outcome, error) {
+			start := time.Now()
+			out, err := next(st)
+			r.Observe(st.Table, st.Kind, time.Since(start), err)
+			return out, err
+		}
+	}
+}
+
+// Observe records one statement.
+func (r *Registry) Observe(table string, kind gom.StmtKind, d time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := key{table, kind}
+	s, ok := r.series[k]
+	if !ok {
+		s = &series{buckets: make([]uint64, len(r.buckets))}
+		r.series[k] = s
+	}
+	s.count++
+	if err != nil {
+		s.errors++
+	}
+	sec := d.Seconds()
+	s.sum += sec
+	for i, le := range r.buckets {
+		if sec <= le {
+			s.buckets[i]++
+			break
+		}
+	}
+}
+
+// Snapshot returns a copy of all series ordered by table and kind.
+func (r *Registry) Snapshot() []Sample {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]Sample, 0, len(r.series))
+	for k, s := range r.series {
+		sm := Sample{
+			Table:    k.table,
+			Kind:     k.kind,
+			Count:    s.count,
+			Errors:   s.errors,
+			Sum:      time.Duration(s.sum * float64(time.Second)),
+			Buckets:  r.buckets,
+			Observed: make([]uint64, len(s.buckets)),
+		}
+		var acc uint64
+		for i, n := range s.buckets {
+			acc += n
+			sm.Observed[i] = acc
+		}
+		out = append(out, sm)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Table != out[j].Table {
+			return out[i].Table < out[j].Table
+		}
+		return out[i].Kind < out[j].Kind
+	})
+	return out
+}
+
+// Reset drops all collected series.
+func (r *Registry) Reset() {
+	r.mu.Lock()
+	r.series = make(map[key]*series)
+	r.mu.Unlock()
+}
+
+// WriteTo writes all metrics in the Prometheus text format.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	samples := r.Snapshot()
+	var b strings.Builder
+
+	total := r.namespace + "_queries_total"
+	errs := r.namespace + "_query_errors_total"
+	dur := r.namespace + "_query_duration_seconds"
+
+	fmt.Fprintf(&b, "# HELP %s Statements executed.\n# TYPE %s counter\n", total, total)
+	for _, s := range samples {
+		fmt.Fprintf(&b, "%s{%s} %d\n", total, labels(s), s.Count)
+	}
+	fmt.Fprintf(&b, "# HELP %s Statements that returned an error.\n# TYPE %s counter\n", errs, errs)
+	for _, s := range samples {
+		fmt.Fprintf(&b, "%s{%s} %d\n", errs, labels(s), s.Errors)
+	}
+	fmt.Fprintf(&b, "# HELP %s Statement latency.\n# TYPE %s histogram\n", dur, dur)
+	for _, s := range samples {
+		l := labels(s)
+		for i, le := range s.Buckets {
+			fmt.Fprintf(&b, "%s_bucket{%s,le=\"%s\"} %d\n", dur, l, formatFloat(le), s.Observed[i])
+		}
+		fmt.Fprintf(&b, "%s_bucket{%s,le=\"+Inf\"} %d\n", dur, l, s.Count)
+		fmt.Fprintf(&b, "%s_sum{%s} %s\n", dur, l, formatFloat(s.Sum.Seconds()))
+		fmt.Fprintf(&b, "%s_count{%s} %d\n", dur, l, s.Count)
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// ServeHTTP exposes the registry as a scrape endpoint.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	r.WriteTo(w)
+}
+
+func labels(s Sample) string {
+	return fmt.Sprintf("table=%s,kind=%s", quote(s.Table), quote(string(s.Kind)))
+}
+
+func quote(v string) string {
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, "\n", `\n`)
+	v = strings.ReplaceAll(v, `"`, `\"`)
+	return `"` + v + `"`
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gkyh/gom"
+)
+
+func TestWriteTo(t *testing.T) {
+	r := NewRegistry("app", 1, 0.5)
+	r.Observe("tb_user", gom.StmtSelect, 250*time.Millisecond, nil)
+	r.Observe("tb_user", gom.StmtSelect, 2*time.Second, errors.New("boom"))
+	r.Observe("tb_user", gom.StmtInsert, time.Second, nil)
+	r.Observe(`tb_"x"`, gom.StmtDelete, 0, nil)
+
+	want := `# HELP app_queries_total Statements executed.
+# TYPE app_queries_total counter
+app_queries_total{table="tb_\"x\"",kind="delete"} 1
+app_queries_total{table="tb_user",kind="insert"} 1
+app_queries_total{table="tb_user",kind="select"} 2
+# HELP app_query_errors_total Statements that returned an error.
+# TYPE app_query_errors_total counter
+app_query_errors_total{table="tb_\"x\"",kind="delete"} 0
+app_query_errors_total{table="tb_user",kind="insert"} 0
+app_query_errors_total{table="tb_user",kind="select"} 1
+# HELP app_query_duration_seconds Statement latency.
+# TYPE app_query_duration_seconds histogram
+app_query_duration_seconds_bucket{table="tb_\"x\"",kind="delete",le="0.5"} 1
+app_query_duration_seconds_bucket{table="tb_\"x\"",kind="delete",le="1"} 1
+app_query_duration_seconds_bucket{table="tb_\"x\"",kind="delete",le="+Inf"} 1
+app_query_duration_seconds_sum{table="tb_\"x\"",kind="delete"} 0
+app_query_duration_seconds_count{table="tb_\"x\"",kind="delete"} 1
+app_query_duration_seconds_bucket{table="tb_user",kind="insert",le="0.5"} 0
+app_query_duration_seconds_bucket{table="tb_user",kind="insert",le="1"} 1
+app_query_duration_seconds_bucket{table="tb_user",kind="insert",le="+Inf"} 1
+app_query_duration_seconds_sum{table="tb_user",kind="insert"} 1
+app_query_duration_seconds_count{table="tb_user",kind="insert"} 1
+app_query_duration_seconds_bucket{table="tb_user",kind="select",le="0.5"} 1
+app_query_duration_seconds_bucket{table="tb_user",kind="select",le="1"} 1
+app_query_duration_seconds_bucket{table="tb_user",kind="select",le="+Inf"} 2
+app_query_duration_seconds_sum{table="tb_user",kind="select"} 2.25
+app_query_duration_seconds_count{table="tb_user",kind="select"} 2
+`
+	var b strings.Builder
+	n, err := r.WriteTo(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != want {
+		t.Errorf("WriteTo output:\n%s\nwant:\n%s", b.String(), want)
+	}
+	if n != int64(len(want)) {
+		t.Errorf("WriteTo returned %d, wrote %d bytes", n, len(want))
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"plain", `"plain"`},
+		{`a"b`, `"a\"b"`},
+		{`a\b`, `"a\\b"`},
+		{"a\nb", `"a\nb"`},
+	}
+	for _, tt := range tests {
+		if got := quote(tt.in); got != tt.want {
+			t.Errorf("quote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestInterceptor(t *testing.T) {
+	r := NewRegistry("")
+	db := (&gom.ConDB{}).DryRun()
+	db.Use(r.Interceptor())
+
+	db.Table("tb_user").Where("id = ?", 1).Count()
+	db.Table("tb_user").Where("id = ?", 1).UpdateMap(map[string]interface{}{"name": "x"})
+	db.Table("tb_user").Where("id = ?", 2).UpdateMap(map[string]interface{}{"name": "y"})
+
+	got := map[gom.StmtKind]uint64{}
+	for _, s := range r.Snapshot() {
+		if s.Table != "tb_user" {
+			t.Errorf("unexpected table %q", s.Table)
+		}
+		got[s.Kind] = s.Count
+	}
+	if got[gom.StmtSelect] != 1 || got[gom.StmtUpdate] != 2 {
+		t.Errorf("counts = %v, want 1 select and 2 updates", got)
+	}
+
+	r.Reset()
+	if s := r.Snapshot(); len(s) != 0 {
+		t.Errorf("Snapshot after Reset = %v", s)
+	}
+}
@@ -0,0 +1,133 @@
+package gom
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// namedSource 判断参数是否为命名参数来源：唯一的 map[string]interface{} 或结构体
+func namedSource(args []interface{}) (interface{}, bool) {
+	if len(args) != 1 || args[0] == nil {
+		return nil, false
+	}
+	switch args[0].(type) {
+	case map[string]interface{}:
+		return args[0], true
+	case time.Time, *time.Time, driver.Valuer:
+		return nil, false
+	}
+	v := reflect.Indirect(reflect.ValueOf(args[0]))
+	if v.Kind() != reflect.Struct {
+		return nil, false
+	}
+	return args[0], true
+}
+
+// namedLookup 按名称取值：map 直接取键，结构体按 db 标签、gom 标签或字段名
+func namedLookup(src interface{}, name string) (interface{}, bool) {
+	if m, ok := src.(map[string]interface{}); ok {
+		v, ok := m[name]
+		return v, ok
+	}
+	v := reflect.Indirect(reflect.ValueOf(src))
+	idx, ok := getFieldMap(v.Type())[strings.ToLower(name)]
+	if !ok {
+		return nil, false
+	}
+	return v.FieldByIndex(idx.Index).Interface(), true
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// bindNamed 将 :name / @name 替换为 ?，切片展开为 ?, ?, ?（空切片为 NULL）。
+// 引号内的内容、:: 类型转换、:= 赋值、@@ 系统变量以及来源中没有的 @name
+// 不会被当作参数；
+// found 为 false 表示语句中没有命名参数
+func bindNamed(query string, src interface{}) (string, []interface{}, bool, error) {
+	var sb strings.Builder
+	var args []interface{}
+	found := false
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		if quote != 0 {
+			sb.WriteByte(c)
+			if c == '\\' && i+1 < len(query) {
+				i++
+				sb.WriteByte(query[i])
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"', '`':
+			quote = c
+			sb.WriteByte(c)
+			continue
+		case ':', '@':
+			if i+1 < len(query) && isNameChar(query[i+1]) && (i == 0 || !isNameChar(query[i-1]) && query[i-1] != ':' && query[i-1] != '@') {
+				j := i + 1
+				for j < len(query) && isNameChar(query[j]) {
+					j++
+				}
+				name := query[i+1 : j]
+				v, ok := namedLookup(src, name)
+				if !ok && c == '@' {
+					// 未提供的 @name 视为 MySQL 用户变量，原样保留
+					sb.WriteString(query[i:j])
+					i = j - 1
+					continue
+				}
+				if !ok {
+					return "", nil, true, fmt.Errorf("gom: missing value for named parameter %c%s", c, name)
+				}
+				found = true
+				args = appendNamed(&sb, args, v)
+				i = j - 1
+				continue
+			}
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String(), args, found, nil
+}
+
+// appendNamed 写入占位符；切片（[]byte 除外）展开为多个参数
+func appendNamed(sb *strings.Builder, args []interface{}, v interface{}) []interface{} {
+	rv := reflect.ValueOf(v)
+	if (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) && rv.Type().Elem().Kind() != reflect.Uint8 {
+		if rv.Len() == 0 {
+			sb.WriteString("NULL")
+			return args
+		}
+		for k := 0; k < rv.Len(); k++ {
+			if k > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteByte('?')
+			args = append(args, rv.Index(k).Interface())
+		}
+		return args
+	}
+	sb.WriteByte('?')
+	return append(args, v)
+}
+
+// named 在参数为单个 map 或结构体且语句含命名参数时完成绑定，否则原样返回
+func named(query string, args []interface{}) (string, []interface{}, error) {
+	src, ok := namedSource(args)
+	if !ok {
+		return query, args, nil
+	}
+	q, bound, found, err := bindNamed(query, src)
+	if err != nil || !found {
+		return query, args, err
+	}
+	return q, bound, nil
+}
@@ -0,0 +1,83 @@
+package gom
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBindNamed(t *testing.T) {
+	type user struct {
+		Id   int64  `db:"id"`
+		Name string `db:"name"`
+	}
+	src := map[string]interface{}{
+		"id":    1,
+		"name":  "bob",
+		"ids":   []int{1, 2, 3},
+		"none":  []int{},
+		"token": []byte("ab"),
+	}
+	tests := []struct {
+		name  string
+		query string
+		src   interface{}
+		want  string
+		args  []interface{}
+		found bool
+	}{
+		{"colon", "id = :id", src, "id = ?", []interface{}{1}, true},
+		{"at", "name = @name AND id = :id", src, "name = ? AND id = ?", []interface{}{"bob", 1}, true},
+		{"repeated", ":id OR :id", src, "? OR ?", []interface{}{1, 1}, true},
+		{"slice", "id IN (:ids)", src, "id IN (?, ?, ?)", []interface{}{1, 2, 3}, true},
+		{"empty slice", "id IN (:none)", src, "id IN (NULL)", nil, true},
+		{"bytes", "token = :token", src, "token = ?", []interface{}{[]byte("ab")}, true},
+		{"quoted", "note = ':id' AND id = :id", src, "note = ':id' AND id = ?", []interface{}{1}, true},
+		{"escaped quote", `note = 'it\'s :id' AND id = :id`, src, `note = 'it\'s :id' AND id = ?`, []interface{}{1}, true},
+		{"cast", "created::date = :id", src, "created::date = ?", []interface{}{1}, true},
+		{"assign", "SET @v := :id", src, "SET @v := ?", []interface{}{1}, true},
+		{"system variable", "SELECT @@session.time_zone", src, "SELECT @@session.time_zone", nil, false},
+		{"user variable", "SELECT @rank + :id", src, "SELECT @rank + ?", []interface{}{1}, true},
+		{"no params", "SELECT 1", src, "SELECT 1", nil, false},
+		{"struct", "id = :id AND name = :Name", user{Id: 7, Name: "ann"}, "id = ? AND name = ?", []interface{}{int64(7), "ann"}, true},
+		{"struct pointer", "id = :id", &user{Id: 8}, "id = ?", []interface{}{int64(8)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args, found, err := bindNamed(tt.query, tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want || found != tt.found || !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("bindNamed(%q) = %q, %v, %v; want %q, %v, %v", tt.query, got, args, found, tt.want, tt.args, tt.found)
+			}
+		})
+	}
+}
+
+func TestBindNamedMissing(t *testing.T) {
+	for _, q := range []string{"id = :nope", "id = :id AND x = :nope"} {
+		if _, _, _, err := bindNamed(q, map[string]interface{}{"id": 1}); err == nil {
+			t.Errorf("bindNamed(%q) did not report the missing parameter", q)
+		}
+	}
+}
+
+func TestNamedSource(t *testing.T) {
+	tests := []struct {
+		name string
+		args []interface{}
+		ok   bool
+	}{
+		{"map", []interface{}{map[string]interface{}{"id": 1}}, true},
+		{"struct", []interface{}{struct{ Id int }{1}}, true},
+		{"positional", []interface{}{1}, false},
+		{"two args", []interface{}{map[string]interface{}{}, 1}, false},
+		{"nil", []interface{}{nil}, false},
+		{"none", nil, false},
+	}
+	for _, tt := range tests {
+		if _, ok := namedSource(tt.args); ok != tt.ok {
+			t.Errorf("%s: namedSource ok = %v, want %v", tt.name, ok, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package gom
+
+import (
+	"errors"
+	"sync"
+)
+
+// Pagination is the page metadata returned by Paginate.
+type Pagination struct {
+	Page    int32
+	Size    int32
+	Total   int64
+	Pages   int64
+	HasNext bool
+	HasPrev bool
+}
+
+// Paginate loads page (1-based) of size rows into out and counts all rows
+// matching the chain's conditions, ignoring its ORDER BY and LIMIT.
+func (m *ConDB) Paginate(page, size int32, out interface{}) (Pagination, error) {
+	return m.paginate(page, size, out, false)
+}
+
+// PaginateConcurrently is Paginate with the count and the page query run in
+// parallel. Inside a transaction both run sequentially on the tx.
+func (m *ConDB) PaginateConcurrently(page, size int32, out interface{}) (Pagination, error) {
+	return m.paginate(page, size, out, m.tx == nil)
+}
+
+func (m *ConDB) paginate(page, size int32, out interface{}, concurrent bool) (Pagination, error) {
+	if m.parent == nil {
+		return Pagination{}, errors.New("Lack of ConDB objects")
+	}
+	if size <= 0 {
+		return Pagination{}, errors.New("gom: page size must be positive")
+	}
+	if page < 1 {
+		page = 1
+	}
+	if m.builder.table == "" {
+		m.builder.From(getTable(out))
+	}
+
+	counter := m.fork()
+	finder := m.fork()
+	finder.Page(page, size)
+
+	var total int64
+	var countErr, findErr error
+	count := func() {
+		total = counter.Count()
+		countErr = counter.Err
+	}
+	if concurrent {
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			count()
+		}()
+		findErr = finder.Find(out)
+		wg.Wait()
+	} else {
+		count()
+		if countErr == nil {
+			findErr = finder.Find(out)
+		}
+	}
+	if countErr != nil {
+		return Pagination{}, countErr
+	}
+	if findErr != nil {
+		return Pagination{}, findErr
+	}
+
+	p := Pagination{Page: page, Size: size, Total: total}
+	p.Pages = (total + int64(size) - 1) / int64(size)
+	p.HasNext = int64(page) < p.Pages
+	p.HasPrev = page > 1
+	return p, nil
+}
+
+// fork 复制当前链及其 builder，两者互不影响
+func (m *ConDB) fork() *ConDB {
+	db := *m
+	b := *m.builder
+	b.clauses = append([]clause{}, m.builder.clauses...)
+	db.builder = &b
+	db.Err = nil
+	return &db
+}
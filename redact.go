@@ -0,0 +1,194 @@
+package gom
+
+import (
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// redactMask 替换被屏蔽参数的文本
+const redactMask = "******"
+
+var redaction = struct {
+	sync.RWMutex
+	patterns []string
+	tagged   map[string]bool // sensitive:"true" 标记的列
+}{tagged: make(map[string]bool)}
+
+// RedactColumns masks logged arguments bound to columns whose name matches
+// one of patterns (path.Match syntax, case-insensitive), e.g. "password",
+// "*_token", "id_card*". Only trace output is affected; the database still
+// receives the values.
+//
+// Fields tagged sensitive:"true" are masked too, but a tag is only seen
+// once its struct has been reflected (RegisterModel, Model, Insert, Save or
+// a read into it); register models at startup so Table, UpdateMap and Raw
+// statements are masked from the first call. Masking is by column name, so
+// a tagged column is masked in every table that has a column of that name.
+func (m *ConDB) RedactColumns(patterns ...string) {
+	redaction.Lock()
+	defer redaction.Unlock()
+	redaction.patterns = redaction.patterns[:0]
+	for _, p := range patterns {
+		redaction.patterns = append(redaction.patterns, strings.ToLower(p))
+	}
+}
+
+// RegisterModel reflects models up front so their sensitive:"true" tags
+// and createdBy / updatedBy columns apply to every statement on their
+// tables, including those built with Table or Raw.
+func RegisterModel(models ...interface{}) {
+	for _, model := range models {
+		registerModel(getTable(model), modelType(model))
+	}
+}
+
+// registerModel 反射结构体，登记 sensitive 列和表的操作人列
+func registerModel(table string, t reflect.Type) {
+	if t == nil {
+		return
+	}
+	getFieldMap(t)
+	registerActorTable(table, t)
+}
+
+// RegisterSensitive masks arguments bound to columns in every table, as if
+// a field for each column were tagged sensitive:"true".
+func RegisterSensitive(columns ...string) {
+	for _, c := range columns {
+		markSensitive(c)
+	}
+}
+
+// markSensitive 记录 sensitive:"true" 字段对应的列名
+func markSensitive(column string) {
+	redaction.Lock()
+	redaction.tagged[strings.ToLower(column)] = true
+	redaction.Unlock()
+}
+
+func sensitiveColumn(column string) bool {
+	column = strings.ToLower(column)
+	redaction.RLock()
+	defer redaction.RUnlock()
+	if redaction.tagged[column] {
+		return true
+	}
+	for _, p := range redaction.patterns {
+		if ok, _ := path.Match(p, column); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func redactionEnabled() bool {
+	redaction.RLock()
+	defer redaction.RUnlock()
+	return len(redaction.patterns) > 0 || len(redaction.tagged) > 0
+}
+
+// redactArgs 返回屏蔽了敏感列参数的副本
+func redactArgs(query string, args []interface{}) []interface{} {
+	if len(args) == 0 || !redactionEnabled() {
+		return args
+	}
+	var out []interface{}
+	for k, col := range placeholderColumns(query) {
+		if k >= len(args) {
+			break
+		}
+		if col == "" || !sensitiveColumn(col) {
+			continue
+		}
+		if out == nil {
+			out = append([]interface{}{}, args...)
+		}
+		out[k] = redactMask
+	}
+	if out == nil {
+		return args
+	}
+	return out
+}
+
+// placeholderColumns 按顺序返回每个 ? 绑定的列名（无法判断时为空）：
+// INSERT 按列清单位置对应，其余取占位符前面的列，如 col = ?、col IN (?, ?)、
+// col BETWEEN ? AND ?
+func placeholderColumns(query string) []string {
+	var insertCols []string
+	valuesAt := -1
+	upper := strings.ToUpper(query)
+	if strings.HasPrefix(strings.TrimSpace(upper), "INSERT") {
+		if v := strings.Index(upper, "VALUES"); v > 0 {
+			if open, end := strings.Index(query, "("), strings.LastIndex(query[:v], ")"); open >= 0 && open < end {
+				for _, c := range strings.Split(query[open+1:end], ",") {
+					insertCols = append(insertCols, cleanColumn(c))
+				}
+				valuesAt = v
+			}
+		}
+	}
+
+	var cols []string
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"', '`':
+			quote = c
+		case '?':
+			if valuesAt >= 0 && i > valuesAt && len(insertCols) > 0 {
+				cols = append(cols, insertCols[len(cols)%len(insertCols)])
+			} else {
+				cols = append(cols, columnBefore(query, i))
+			}
+		}
+	}
+	return cols
+}
+
+// columnBefore 从占位符向前跳过运算符、括号、逗号、其他占位符和 IN / LIKE 等关键字，
+// 返回遇到的第一个标识符
+func columnBefore(query string, i int) string {
+	j := i - 1
+	for j >= 0 {
+		c := query[j]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || strings.IndexByte("=<>!(),?", c) >= 0:
+			j--
+		case isNameChar(c) || c == '.' || c == '`':
+			end := j + 1
+			for j >= 0 && (isNameChar(query[j]) || query[j] == '.' || query[j] == '`') {
+				j--
+			}
+			word := query[j+1 : end]
+			switch strings.ToUpper(word) {
+			case "IN", "NOT", "LIKE", "IS", "BETWEEN", "AND", "REGEXP":
+				continue
+			}
+			return cleanColumn(word)
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+// cleanColumn 去掉空白、反引号和表名前缀
+func cleanColumn(c string) string {
+	c = strings.Trim(strings.TrimSpace(c), "`\"")
+	if k := strings.LastIndexByte(c, '.'); k >= 0 {
+		c = strings.Trim(c[k+1:], "`\"")
+	}
+	return c
+}
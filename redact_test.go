@@ -0,0 +1,67 @@
+package gom
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlaceholderColumns(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"SELECT * FROM t WHERE id = ?", []string{"id"}},
+		{"SELECT * FROM t WHERE t.`name` <> ? AND age >= ?", []string{"name", "age"}},
+		{"SELECT * FROM t WHERE id IN (?, ?, ?)", []string{"id", "id", "id"}},
+		{"SELECT * FROM t WHERE id NOT IN (?)", []string{"id"}},
+		{"SELECT * FROM t WHERE name LIKE ? OR name NOT LIKE ?", []string{"name", "name"}},
+		{"SELECT * FROM t WHERE age BETWEEN ? AND ?", []string{"age", "age"}},
+		{"SELECT * FROM t WHERE note = '?' AND id = ?", []string{"id"}},
+		{"UPDATE t SET password = ?, name = ? WHERE id = ?", []string{"password", "name", "id"}},
+		{"INSERT INTO t (id, `password`, name) VALUES (?, ?, ?)", []string{"id", "password", "name"}},
+		{"INSERT INTO t (id, token) VALUES (?, ?), (?, ?)", []string{"id", "token", "id", "token"}},
+		{"SELECT * FROM t WHERE id = 1 + ?", []string{""}},
+	}
+	for _, tt := range tests {
+		if got := placeholderColumns(tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("placeholderColumns(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestRedactArgs(t *testing.T) {
+	(&ConDB{}).RedactColumns("password", "*_token")
+	markSensitive("ID_Card")
+	t.Cleanup(func() {
+		(&ConDB{}).RedactColumns()
+		redaction.Lock()
+		delete(redaction.tagged, "id_card")
+		redaction.Unlock()
+	})
+
+	tests := []struct {
+		name  string
+		query string
+		args  []interface{}
+		want  []interface{}
+	}{
+		{"pattern", "UPDATE t SET password = ? WHERE id = ?", []interface{}{"s3cret", 1}, []interface{}{redactMask, 1}},
+		{"glob", "SELECT * FROM t WHERE access_token = ?", []interface{}{"abc"}, []interface{}{redactMask}},
+		{"tagged", "INSERT INTO t (id, id_card) VALUES (?, ?)", []interface{}{1, "110"}, []interface{}{1, redactMask}},
+		{"case", "SELECT * FROM t WHERE PASSWORD = ?", []interface{}{"x"}, []interface{}{redactMask}},
+		{"in list", "SELECT * FROM t WHERE refresh_token IN (?, ?)", []interface{}{"a", "b"}, []interface{}{redactMask, redactMask}},
+		{"untouched", "SELECT * FROM t WHERE name = ?", []interface{}{"bob"}, []interface{}{"bob"}},
+		{"extra args", "SELECT * FROM t WHERE password = ?", []interface{}{"x", "y"}, []interface{}{redactMask, "y"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orig := append([]interface{}{}, tt.args...)
+			if got := redactArgs(tt.query, tt.args); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("redactArgs(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+			if !reflect.DeepEqual(tt.args, orig) {
+				t.Errorf("redactArgs modified the caller's arguments: %v", tt.args)
+			}
+		})
+	}
+}
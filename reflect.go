@@ -2,6 +2,7 @@ package gom
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"math"
 	"reflect"
@@ -35,6 +36,18 @@ func CamelToSnake(s string) string {
 
 	return string(result)
 }
+// modelType 取 Model 参数的结构体类型（可为指针、切片），否则返回 nil
+func modelType(class interface{}) reflect.Type {
+	t := reflect.TypeOf(class)
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
 func getTable(class interface{}) string {
 
 	var table string
@@ -112,6 +125,11 @@ func RowToStruct(rows *sql.Rows, out interface{}) error {
 	fieldMap := getFieldMap(eleType)
 	element := reflect.ValueOf(out).Elem()
 
+	return scanStruct(rows, cols, fieldMap, element)
+}
+
+// scanStruct 将当前行映射到 element
+func scanStruct(rows *sql.Rows, cols []string, fieldMap map[string]fieldIndex, element reflect.Value) error {
 	scanVals := make([]interface{}, len(cols))
 	scanDests := make([]interface{}, len(cols))
 	for i := range scanVals {
@@ -124,19 +142,29 @@ func RowToStruct(rows *sql.Rows, out interface{}) error {
 
 	for i, col := range cols {
 		raw := scanVals[i]
-		if raw == nil {
-			continue
-		}
-
 		idx, ok := fieldMap[strings.ToLower(col)]
 		if !ok {
 			continue
 		}
 
 		field := element.FieldByIndex(idx.Index)
-		if val, ok := ConvertValue(raw, field.Type(), idx.Tag); ok {
-			field.Set(val)
+		if raw == nil {
+			// NULL：指针和 JSON 字段置零值，Scanner 交给 Scan(nil)，其它类型保持原值
+			if field.Kind() == reflect.Ptr || isJSONField(idx.Tag) {
+				field.Set(reflect.Zero(field.Type()))
+			} else if val, ok := scanInto(nil, field.Type()); ok {
+				field.Set(val)
+			}
+			continue
 		}
+		val, err := convertValue(raw, field.Type(), idx.Tag)
+		if err == errNoConversion {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("column %s: %w", col, err)
+		}
+		field.Set(val)
 
 	}
 
@@ -164,7 +192,9 @@ func RowsToMap(rows *sql.Rows) (map[string]interface{}, error) {
 
 	rowMap := make(map[string]interface{})
 	for i, col := range columns {
-		rowMap[col] = ConvertValueAuto(scanVals[i])
+		if rowMap[col], err = convertColumnAuto(col, scanVals[i]); err != nil {
+			return nil, err
+		}
 	}
 
 	return rowMap, nil
@@ -190,7 +220,9 @@ func RowsToMaps(rows *sql.Rows) ([]map[string]interface{}, error) {
 
 		rowMap := make(map[string]interface{})
 		for i, col := range columns {
-			rowMap[col] = ConvertValueAuto(scanVals[i])
+			if rowMap[col], err = convertColumnAuto(col, scanVals[i]); err != nil {
+				return nil, err
+			}
 		}
 
 		results = append(results, rowMap)
@@ -254,6 +286,9 @@ func collectFields(t reflect.Type, parent []int, out map[string]fieldIndex) {
 		if tag == "" {
 			tag = f.Name
 		}
+		if f.Tag.Get("sensitive") == "true" {
+			markSensitive(tag)
+		}
 		out[strings.ToLower(tag)] = fieldIndex{Index: idx, Type: f.Type, Tag: f.Tag}
 	}
 }
@@ -269,154 +304,285 @@ func RowsToList(rows *sql.Rows, out interface{}) error {
 
 	for rows.Next() {
 		element := reflect.New(eleType).Elem()
-		scanVals := make([]interface{}, len(columns))
-		scanDest := make([]interface{}, len(columns))
-		for i := range scanVals {
-			scanDest[i] = &scanVals[i]
-		}
-
-		if err := rows.Scan(scanDest...); err != nil {
+		if err := scanStruct(rows, columns, fieldMap, element); err != nil {
 			return err
 		}
 
-		for i, col := range columns {
-			raw := scanVals[i]
-			if raw == nil {
-				continue
-			}
-
-			idx, ok := fieldMap[strings.ToLower(col)]
-			if !ok {
-				continue
-			}
-
-			field := element.FieldByIndex(idx.Index)
-			if val, ok := ConvertValue(raw, field.Type(), idx.Tag); ok {
-				field.Set(val)
-			}
-
-		}
-
 		sliceValue.Set(reflect.Append(sliceValue, element))
 	}
 
 	return rows.Err()
 }
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// scanInto 对实现 sql.Scanner 的类型调用 Scan
+func scanInto(raw interface{}, targetType reflect.Type) (reflect.Value, bool) {
+	if !reflect.PtrTo(targetType).Implements(scannerType) {
+		return reflect.Value{}, false
+	}
+	ptr := reflect.New(targetType)
+	if err := ptr.Interface().(sql.Scanner).Scan(raw); err != nil {
+		return reflect.Zero(targetType), false
+	}
+	return ptr.Elem(), true
+}
+
+// ConvertValue converts a driver value to targetType. ok is false when raw
+// is NULL or cannot be represented, including numeric overflow.
 func ConvertValue(raw interface{}, targetType reflect.Type, tag reflect.StructTag) (reflect.Value, bool) {
-	if raw == nil {
+	val, err := convertValue(raw, targetType, tag)
+	if err != nil {
 		return reflect.Zero(targetType), false
 	}
+	return val, true
+}
+
+// errNoConversion 表示无法转换、按原逻辑忽略该字段；其余错误需要返回给调用方
+var errNoConversion = errors.New("gom: no conversion")
+
+func convertValue(raw interface{}, targetType reflect.Type, tag reflect.StructTag) (reflect.Value, error) {
+	if raw == nil {
+		return reflect.Zero(targetType), errNoConversion
+	}
+
+	// 注册的转换器（conv 标签或类型）
+	if val, ok, err := fromDBConverter(raw, targetType, tag); ok {
+		return val, err
+	}
+
+	// type:"json" 列反序列化到 struct / map / slice
+	if isJSONField(tag) {
+		return decodeJSON(raw, targetType)
+	}
+
+	// sql.Scanner（sql.NullString、自定义类型等）
+	if targetType.Kind() != reflect.Ptr {
+		if val, ok := scanInto(raw, targetType); ok {
+			return val, nil
+		} else if val.IsValid() {
+			return val, errNoConversion
+		}
+	}
+
+	// 指针字段：转换元素类型后取地址
+	if targetType.Kind() == reflect.Ptr {
+		val, err := convertValue(raw, targetType.Elem(), tag)
+		if err != nil {
+			return reflect.Zero(targetType), err
+		}
+		ptr := reflect.New(targetType.Elem())
+		ptr.Elem().Set(val)
+		return ptr, nil
+	}
 
 	// 处理 time.Time
 	if targetType == reflect.TypeOf(time.Time{}) {
 		switch v := raw.(type) {
 		case time.Time:
-			return reflect.ValueOf(v), true
+			return reflect.ValueOf(v), nil
 		case []byte:
 			if t, err := parseTime(string(v)); err == nil {
-				return reflect.ValueOf(t), true
+				return reflect.ValueOf(t), nil
 			}
 		case string:
 			if t, err := parseTime(v); err == nil {
-				return reflect.ValueOf(t), true
+				return reflect.ValueOf(t), nil
 			}
 		}
-		return reflect.Zero(targetType), false
+		return reflect.Zero(targetType), errNoConversion
 	}
 
-	// 处理 float64 (如 DECIMAL)
-	if tag.Get("type") == "decimal" {
+	// DECIMAL 映射到 string 字段时保留原始精度
+	if tag.Get("type") == "decimal" && targetType.Kind() == reflect.String {
 		switch v := raw.(type) {
 		case []byte:
-			return reflect.ValueOf(string(v)), true
+			return reflect.ValueOf(string(v)).Convert(targetType), nil
 		case string:
-			return reflect.ValueOf(v), true
+			return reflect.ValueOf(v).Convert(targetType), nil
 		}
 	}
-	if targetType.Kind() == reflect.Float64 {
-		switch v := raw.(type) {
-		case float64:
-			return reflect.ValueOf(v), true
-		case []byte:
-			if f, err := strconv.ParseFloat(string(v), 64); err == nil {
-				return reflect.ValueOf(f), true
-			}
-		case string:
-			if f, err := strconv.ParseFloat(v, 64); err == nil {
-				return reflect.ValueOf(f), true
-			}
-		}
-		return reflect.Zero(targetType), false
-	}
 
-	// 处理 int64（如 BIGINT）
-	if targetType.Kind() == reflect.Int64 {
-		switch v := raw.(type) {
-		case uint64:
-			if v <= math.MaxInt64 {
-				return reflect.ValueOf(int64(v)), true
-			}
-		case int64:
-			return reflect.ValueOf(v), true
-		case []byte:
-			if i, err := strconv.ParseInt(string(v), 10, 64); err == nil {
-				return reflect.ValueOf(i), true
-			}
-		case string:
-			if i, err := strconv.ParseInt(v, 10, 64); err == nil {
-				return reflect.ValueOf(i), true
-			}
-		}
-		return reflect.Zero(targetType), false
+	// 所有整数、浮点类型
+	switch targetType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return convertNumber(raw, targetType)
 	}
 
 	// 处理 bool（BOOLEAN, TINYINT）
 	if targetType.Kind() == reflect.Bool {
 		switch v := raw.(type) {
 		case bool:
-			return reflect.ValueOf(v), true
+			return reflect.ValueOf(v).Convert(targetType), nil
 		case int64:
-			return reflect.ValueOf(v != 0), true
+			return reflect.ValueOf(v != 0).Convert(targetType), nil
 		case []byte:
 			s := string(v)
 			if s == "1" || strings.ToLower(s) == "true" {
-				return reflect.ValueOf(true), true
+				return reflect.ValueOf(true).Convert(targetType), nil
 			} else if s == "0" || strings.ToLower(s) == "false" {
-				return reflect.ValueOf(false), true
+				return reflect.ValueOf(false).Convert(targetType), nil
 			}
 		case string:
 			if v == "1" || strings.ToLower(v) == "true" {
-				return reflect.ValueOf(true), true
+				return reflect.ValueOf(true).Convert(targetType), nil
 			} else if v == "0" || strings.ToLower(v) == "false" {
-				return reflect.ValueOf(false), true
+				return reflect.ValueOf(false).Convert(targetType), nil
 			}
 		}
-		return reflect.Zero(targetType), false
+		return reflect.Zero(targetType), errNoConversion
 	}
 
 	// 处理 string（CHAR, VARCHAR, TEXT）
 	if targetType.Kind() == reflect.String {
 		switch v := raw.(type) {
 		case string:
-			return reflect.ValueOf(v), true
+			return reflect.ValueOf(v).Convert(targetType), nil
 		case []byte:
-			return reflect.ValueOf(string(v)), true
+			return reflect.ValueOf(string(v)).Convert(targetType), nil
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64,
+			float64, float32, bool, time.Time:
+			// 避免 int64 -> string 被当成 rune 转换
+			return reflect.ValueOf(parseString(v)).Convert(targetType), nil
 		}
 	}
 
 	// 默认处理：如果可以转换
 	val := reflect.ValueOf(raw)
 	if val.Kind() == reflect.Ptr && val.IsNil() {
-		return reflect.Zero(targetType), false
+		return reflect.Zero(targetType), errNoConversion
 	}
 	if val.Kind() == reflect.Ptr {
 		val = val.Elem()
 	}
 	if val.IsValid() && val.Type().ConvertibleTo(targetType) {
-		return val.Convert(targetType), true
+		return val.Convert(targetType), nil
 	}
 
-	return reflect.Zero(targetType), false
+	return reflect.Zero(targetType), errNoConversion
+}
+
+// convertNumber 将驱动返回的数值转换为任意整数/浮点类型，越界或无法解析时返回错误
+func convertNumber(raw interface{}, targetType reflect.Type) (reflect.Value, error) {
+	out := reflect.New(targetType).Elem()
+
+	switch targetType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		switch v := raw.(type) {
+		case int64:
+			n = v
+		case uint64:
+			if v > math.MaxInt64 {
+				return out, fmt.Errorf("gom: %d overflows %s", v, targetType)
+			}
+			n = int64(v)
+		case float64:
+			if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+				return out, fmt.Errorf("gom: %v cannot be stored in %s", v, targetType)
+			}
+			n = int64(v)
+		case bool:
+			n = boolInt(v)
+		case []byte, string:
+			s := parseString(v)
+			i, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				// 兼容 "12.0" 这类整数值的小数写法
+				f, ferr := strconv.ParseFloat(s, 64)
+				if ferr != nil || f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+					return out, fmt.Errorf("gom: %q cannot be stored in %s", s, targetType)
+				}
+				i = int64(f)
+			}
+			n = i
+		default:
+			return convertOther(raw, out)
+		}
+		if out.OverflowInt(n) {
+			return out, fmt.Errorf("gom: %d overflows %s", n, targetType)
+		}
+		out.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		switch v := raw.(type) {
+		case int64:
+			if v < 0 {
+				return out, fmt.Errorf("gom: %d overflows %s", v, targetType)
+			}
+			n = uint64(v)
+		case uint64:
+			n = v
+		case float64:
+			if v != math.Trunc(v) || v < 0 || v >= math.MaxUint64 {
+				return out, fmt.Errorf("gom: %v cannot be stored in %s", v, targetType)
+			}
+			n = uint64(v)
+		case bool:
+			n = uint64(boolInt(v))
+		case []byte, string:
+			s := parseString(v)
+			u, err := strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				f, ferr := strconv.ParseFloat(s, 64)
+				if ferr != nil || f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 {
+					return out, fmt.Errorf("gom: %q cannot be stored in %s", s, targetType)
+				}
+				u = uint64(f)
+			}
+			n = u
+		default:
+			return convertOther(raw, out)
+		}
+		if out.OverflowUint(n) {
+			return out, fmt.Errorf("gom: %d overflows %s", n, targetType)
+		}
+		out.SetUint(n)
+
+	default: // Float32, Float64
+		var f float64
+		switch v := raw.(type) {
+		case float64:
+			f = v
+		case float32:
+			f = float64(v)
+		case int64:
+			f = float64(v)
+		case uint64:
+			f = float64(v)
+		case []byte, string:
+			s := parseString(v)
+			var err error
+			if f, err = strconv.ParseFloat(s, 64); err != nil {
+				return out, fmt.Errorf("gom: %q cannot be stored in %s", s, targetType)
+			}
+		default:
+			return convertOther(raw, out)
+		}
+		if out.OverflowFloat(f) {
+			return out, fmt.Errorf("gom: %v overflows %s", f, targetType)
+		}
+		out.SetFloat(f)
+	}
+	return out, nil
+}
+
+// convertOther 处理其它驱动返回的数值类型（如 int32），经 int64/uint64/float64 中转
+func convertOther(raw interface{}, out reflect.Value) (reflect.Value, error) {
+	v := reflect.ValueOf(raw)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return convertNumber(v.Int(), out.Type())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return convertNumber(v.Uint(), out.Type())
+	case reflect.Float32, reflect.Float64:
+		return convertNumber(v.Float(), out.Type())
+	case reflect.String:
+		return convertNumber(v.String(), out.Type())
+	}
+	return out, errNoConversion
 }
 
 func parseTime(s string) (time.Time, error) {
@@ -433,6 +599,9 @@ func parseTime(s string) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("invalid time format: %s", s)
 }
 
+// ConvertValueAuto 按值推断 map 结果的类型。此处只有驱动类型（多为 []byte），
+// 不知道列对应的 Go 类型，因此不按类型应用转换器（否则会改写所有文本列）；
+// map 结果中需要转换的列通过 RegisterResultConverter 按列名登记
 func ConvertValueAuto(raw interface{}) interface{} {
 	switch v := raw.(type) {
 	case nil:
@@ -0,0 +1,60 @@
+package gom
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestConvertNumber(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     interface{}
+		target  interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{"int64 to int8", int64(127), int8(0), int8(127), false},
+		{"int8 overflow", int64(128), int8(0), nil, true},
+		{"int8 underflow", int64(-129), int8(0), nil, true},
+		{"int16 from bytes", []byte("-32768"), int16(0), int16(-32768), false},
+		{"int16 bytes overflow", []byte("32768"), int16(0), nil, true},
+		{"uint64 to int64 overflow", uint64(math.MaxInt64 + 1), int64(0), nil, true},
+		{"uint64 to int64", uint64(math.MaxInt64), int64(0), int64(math.MaxInt64), false},
+		{"whole float to int", float64(42), int32(0), int32(42), false},
+		{"fractional float to int", 1.5, int(0), nil, true},
+		{"huge float to int64", 1e19, int64(0), nil, true},
+		{"decimal string to int", "12.0", int(0), int(12), false},
+		{"fractional string to int", "12.5", int(0), nil, true},
+		{"garbage string to int", "abc", int(0), nil, true},
+		{"bool to int", true, int(0), int(1), false},
+		{"negative to uint", int64(-1), uint(0), nil, true},
+		{"uint8 overflow", int64(256), uint8(0), nil, true},
+		{"uint8 max", int64(255), uint8(0), uint8(255), false},
+		{"uint64 from bytes", []byte("18446744073709551615"), uint64(0), uint64(math.MaxUint64), false},
+		{"uint32 bytes overflow", []byte("4294967296"), uint32(0), nil, true},
+		{"negative float to uint", -1.0, uint(0), nil, true},
+		{"float32 overflow", 1e300, float32(0), nil, true},
+		{"float32", 1.5, float32(0), float32(1.5), false},
+		{"float64 from int", int64(3), float64(0), float64(3), false},
+		{"float from bytes", []byte("2.25"), float64(0), 2.25, false},
+		{"float garbage", "x", float64(0), nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := convertNumber(tt.raw, reflect.TypeOf(tt.target))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("convertNumber(%v) = %v, want error", tt.raw, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Interface() != tt.want {
+				t.Errorf("convertNumber(%v) = %v (%T), want %v (%T)", tt.raw, got, got.Interface(), tt.want, tt.want)
+			}
+		})
+	}
+}
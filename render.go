@@ -0,0 +1,178 @@
+package gom
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dialect selects the literal syntax used by RenderSQL.
+type Dialect string
+
+const (
+	MySQL    Dialect = "mysql"
+	Postgres Dialect = "postgres"
+	SQLite   Dialect = "sqlite"
+)
+
+var logDialect Dialect // 非空时 trace 输出插值后的 SQL
+
+// TraceRendered makes the trace logger print statements with their
+// arguments interpolated in dialect d, so they can be pasted into a
+// client. An empty d restores the "SQL [1:arg ...]" form.
+func (m *ConDB) TraceRendered(d Dialect) {
+	logDialect = d
+}
+
+// RenderSQL interpolates args into query's placeholders as MySQL literals.
+// The result is meant for logs and debugging, never for execution.
+func RenderSQL(query string, args ...interface{}) string {
+	return MySQL.Render(query, args...)
+}
+
+// Render interpolates args into query's placeholders: "?" for every
+// dialect and also "$n" for Postgres. Placeholders inside quoted text are
+// left alone, as are placeholders without a matching argument.
+func (d Dialect) Render(query string, args ...interface{}) string {
+	var sb strings.Builder
+	next := 0
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		if quote != 0 {
+			sb.WriteByte(c)
+			if c == '\\' && d == MySQL && i+1 < len(query) {
+				i++
+				sb.WriteByte(query[i])
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '?' && next < len(args):
+			sb.WriteString(d.Literal(args[next]))
+			next++
+			continue
+		case c == '$' && d == Postgres && i+1 < len(query) && query[i+1] >= '0' && query[i+1] <= '9':
+			j := i + 1
+			for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+				j++
+			}
+			if n, err := strconv.Atoi(query[i+1 : j]); err == nil && n >= 1 && n <= len(args) {
+				sb.WriteString(d.Literal(args[n-1]))
+				i = j - 1
+				continue
+			}
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+// Literal renders v as a SQL literal: NULL for nil, quoted and escaped
+// strings, quoted times, hex bytes and plain numbers.
+func (d Dialect) Literal(v interface{}) string {
+	if valuer, ok := v.(driver.Valuer); ok {
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			return "NULL"
+		}
+		val, err := valuer.Value()
+		if err != nil {
+			return d.quote(fmt.Sprintf("%v", v))
+		}
+		v = val
+	}
+	switch x := v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return d.quote(x)
+	case []byte:
+		if x == nil {
+			return "NULL"
+		}
+		if d == Postgres {
+			return `'\x` + hex.EncodeToString(x) + `'`
+		}
+		return "X'" + hex.EncodeToString(x) + "'"
+	case time.Time:
+		return "'" + x.Format("2006-01-02 15:04:05.999999") + "'"
+	case bool:
+		if d == Postgres {
+			return strings.ToUpper(strconv.FormatBool(x))
+		}
+		if x {
+			return "1"
+		}
+		return "0"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return parseString(x)
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return "NULL"
+		}
+		return d.Literal(rv.Elem().Interface())
+	}
+	switch rv.Kind() {
+	case reflect.String:
+		return d.quote(rv.String())
+	case reflect.Bool:
+		return d.Literal(rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 64)
+	}
+	return d.quote(fmt.Sprintf("%v", v))
+}
+
+// quote 转义字符串：MySQL 额外转义反斜杠和控制字符，其余方言只转义单引号
+func (d Dialect) quote(s string) string {
+	if d != MySQL {
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	}
+	var sb strings.Builder
+	sb.WriteByte('\'')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\'':
+			sb.WriteString(`\'`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case 0:
+			sb.WriteString(`\0`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case 0x1a:
+			sb.WriteString(`\Z`)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	sb.WriteByte('\'')
+	return sb.String()
+}
+
+// traceArgs 展开 trace(sql, args) 这种以切片整体传入的参数
+func traceArgs(args []interface{}) []interface{} {
+	if len(args) == 1 {
+		if inner, ok := args[0].([]interface{}); ok {
+			return inner
+		}
+	}
+	return args
+}
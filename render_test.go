@@ -0,0 +1,82 @@
+package gom
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestDialectRender(t *testing.T) {
+	tests := []struct {
+		name  string
+		d     Dialect
+		query string
+		args  []interface{}
+		want  string
+	}{
+		{"mysql", MySQL, "SELECT * FROM t WHERE id = ? AND name = ?", []interface{}{1, "bob"}, "SELECT * FROM t WHERE id = 1 AND name = 'bob'"},
+		{"quoted placeholder", MySQL, "SELECT '?' FROM t WHERE id = ?", []interface{}{2}, "SELECT '?' FROM t WHERE id = 2"},
+		{"escaped quote", MySQL, `SELECT 'a\'?' , ?`, []interface{}{3}, `SELECT 'a\'?' , 3`},
+		{"backtick", MySQL, "SELECT `a?` FROM t WHERE id = ?", []interface{}{4}, "SELECT `a?` FROM t WHERE id = 4"},
+		{"missing arg", MySQL, "id = ? AND x = ?", []interface{}{1}, "id = 1 AND x = ?"},
+		{"postgres numbered", Postgres, "id = $2 AND name = $1", []interface{}{"bob", 5}, "id = 5 AND name = 'bob'"},
+		{"postgres out of range", Postgres, "id = $3", []interface{}{1}, "id = $3"},
+		{"postgres question", Postgres, "id = ?", []interface{}{true}, "id = TRUE"},
+		{"sqlite dollar", SQLite, "id = $1", []interface{}{1}, "id = $1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.d.Render(tt.query, tt.args...); got != tt.want {
+				t.Errorf("Render(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDialectLiteral(t *testing.T) {
+	ts := time.Date(2024, 5, 6, 7, 8, 9, 120000000, time.UTC)
+	var nilPtr *int
+	n := 9
+	tests := []struct {
+		name string
+		d    Dialect
+		v    interface{}
+		want string
+	}{
+		{"nil", MySQL, nil, "NULL"},
+		{"nil pointer", MySQL, nilPtr, "NULL"},
+		{"pointer", MySQL, &n, "9"},
+		{"int", MySQL, int64(-3), "-3"},
+		{"uint", MySQL, uint8(7), "7"},
+		{"float", MySQL, 1.5, "1.5"},
+		{"mysql bool", MySQL, true, "1"},
+		{"postgres bool", Postgres, false, "FALSE"},
+		{"mysql string", MySQL, "it's\n\\", `'it\'s\n\\'`},
+		{"postgres string", Postgres, `it's \`, `'it''s \'`},
+		{"sqlite string", SQLite, "a'b", "'a''b'"},
+		{"mysql bytes", MySQL, []byte{0xab, 0x01}, "X'ab01'"},
+		{"postgres bytes", Postgres, []byte{0xab}, `'\xab'`},
+		{"nil bytes", MySQL, []byte(nil), "NULL"},
+		{"time", MySQL, ts, "'2024-05-06 07:08:09.12'"},
+		{"valuer", MySQL, sql.NullString{String: "x", Valid: true}, "'x'"},
+		{"null valuer", MySQL, sql.NullInt64{}, "NULL"},
+		{"named string", MySQL, StmtSelect, "'select'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.d.Literal(tt.v); got != tt.want {
+				t.Errorf("Literal(%#v) = %s, want %s", tt.v, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTraceArgs(t *testing.T) {
+	flat := traceArgs([]interface{}{[]interface{}{1, "a"}})
+	if len(flat) != 2 || flat[0] != 1 || flat[1] != "a" {
+		t.Errorf("traceArgs did not flatten a single argument slice: %v", flat)
+	}
+	if got := traceArgs([]interface{}{1, 2}); len(got) != 2 {
+		t.Errorf("traceArgs changed plain arguments: %v", got)
+	}
+}
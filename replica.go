@@ -0,0 +1,136 @@
+package gom
+
+import (
+	"database/sql"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ReplicaPolicy decides which replica serves a read.
+type ReplicaPolicy int
+
+const (
+	RoundRobin ReplicaPolicy = iota
+	Random
+	LeastLatency // 最近延迟（指数加权平均）最低者优先，并定期探测其余副本
+)
+
+// replicaProbeInterval 是 LeastLatency 下副本多久未被测量就探测一次，
+// 否则一次慢查询会让该副本再也不被选中、延迟也无从更新
+const replicaProbeInterval = 5 * time.Second
+
+type replica struct {
+	db      *sql.DB
+	latency int64 // EWMA，纳秒
+	sampled int64 // 最近一次测量或探测的时间，UnixNano
+}
+
+type replicaPool struct {
+	policy   ReplicaPolicy
+	replicas []*replica
+	index    map[*sql.DB]*replica
+	next     uint64
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// SetReplicas attaches read replicas to the root ConDB. Selects issued by
+// Find, Get, Count, List, Scan and friends outside a transaction go to a
+// replica chosen by policy; writes, GetForUpdate, statements inside a
+// transaction and chains marked UsePrimary go to Db. Calling it with no
+// replicas turns splitting off.
+func (m *ConDB) SetReplicas(policy ReplicaPolicy, dbs ...*sql.DB) *ConDB {
+	root := m.root()
+	if len(dbs) == 0 {
+		root.replicas = nil
+		return m
+	}
+	p := &replicaPool{
+		policy: policy,
+		index:  make(map[*sql.DB]*replica, len(dbs)),
+		rnd:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	now := time.Now().UnixNano()
+	for _, db := range dbs {
+		r := &replica{db: db, sampled: now}
+		p.replicas = append(p.replicas, r)
+		p.index[db] = r
+	}
+	root.replicas = p
+	return m
+}
+
+// UsePrimary forces the chain's reads onto the primary, e.g. to read back a
+// row just written.
+func (m *ConDB) UsePrimary() *ConDB {
+	if m.parent == nil {
+		db := m.clone()
+		db.primary = true
+		return db
+	} else {
+		m.primary = true
+		return m
+	}
+}
+
+func (p *replicaPool) pick() *sql.DB {
+	switch p.policy {
+	case Random:
+		p.mu.Lock()
+		i := p.rnd.Intn(len(p.replicas))
+		p.mu.Unlock()
+		return p.replicas[i].db
+	case LeastLatency:
+		// 过期未测量的副本让出一次请求用于重新测量，CAS 保证只有一个请求去探测
+		now := time.Now().UnixNano()
+		for _, r := range p.replicas {
+			last := atomic.LoadInt64(&r.sampled)
+			if now-last > int64(replicaProbeInterval) && atomic.CompareAndSwapInt64(&r.sampled, last, now) {
+				return r.db
+			}
+		}
+		best := p.replicas[0]
+		bestLat := atomic.LoadInt64(&best.latency)
+		for _, r := range p.replicas[1:] {
+			if lat := atomic.LoadInt64(&r.latency); lat < bestLat {
+				best, bestLat = r, lat
+			}
+		}
+		return best.db
+	default:
+		n := atomic.AddUint64(&p.next, 1)
+		return p.replicas[(n-1)%uint64(len(p.replicas))].db
+	}
+}
+
+// measure 记录副本耗时，供 LeastLatency 使用
+func (p *replicaPool) measure(next Handler) Handler {
+	return func(st *Statement) (*Outcome, error) {
+		db, ok := st.Executor.(*sql.DB)
+		if !ok {
+			return next(st)
+		}
+		r, ok := p.index[db]
+		if !ok {
+			return next(st)
+		}
+		start := time.Now()
+		out, err := next(st)
+		d := int64(time.Since(start))
+		atomic.StoreInt64(&r.sampled, time.Now().UnixNano())
+		for {
+			old := atomic.LoadInt64(&r.latency)
+			lat := d
+			if old != 0 {
+				lat = old + (d-old)/5
+			}
+			if atomic.CompareAndSwapInt64(&r.latency, old, lat) {
+				break
+			}
+		}
+		return out, err
+	}
+}
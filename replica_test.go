@@ -0,0 +1,120 @@
+package gom
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type replicaDoc struct {
+	Id    int64  `db:"id"`
+	Title string `db:"title"`
+}
+
+func replicaDB(t *testing.T, policy ReplicaPolicy) (*ConDB, *testDriver, []*testDriver) {
+	primary := &testDriver{}
+	db := &ConDB{Db: primary.open(t)}
+	reps := []*testDriver{{}, {}}
+	db.SetReplicas(policy, reps[0].open(t), reps[1].open(t))
+	return db, primary, reps
+}
+
+func TestReplicaRouting(t *testing.T) {
+	db, primary, reps := replicaDB(t, RoundRobin)
+	table := getTable(replicaDoc{})
+	var out []replicaDoc
+	var one replicaDoc
+
+	for i := 0; i < 3; i++ {
+		if err := db.Where("id = ?", i).Find(&out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Table(table).Count()
+	if _, err := db.Table(table).List(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 以下均走主库
+	if err := db.UsePrimary().Where("id = ?", 1).Find(&out); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Insert(&replicaDoc{Title: "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Table(table).Where("id = ?", 1).Update("title = ?", "y"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Where("id = ?", 1).GetForUpdate(&one); err != nil && err != sql.ErrNoRows {
+		t.Fatal(err)
+	}
+	tx := db.TxBegin()
+	if err := tx.Where("id = ?", 1).Find(&out); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	find := "SELECT * FROM " + table + " WHERE id = ?"
+	if got, want := reps[0].statements(), []string{find, find, "SELECT * FROM " + table}; !reflect.DeepEqual(got, want) {
+		t.Errorf("replica 0 = %q, want %q", got, want)
+	}
+	if got, want := reps[1].statements(), []string{find, "SELECT COUNT(*) FROM " + table}; !reflect.DeepEqual(got, want) {
+		t.Errorf("replica 1 = %q, want %q", got, want)
+	}
+	want := []string{
+		find,
+		"INSERT INTO " + table + " (title) VALUES (?)",
+		"UPDATE " + table + " SET title = ? WHERE id = ?",
+		"SELECT * FROM " + table + " WHERE id = ? for update",
+		"BEGIN",
+		find,
+		"COMMIT",
+	}
+	if got := primary.statements(); !reflect.DeepEqual(got, want) {
+		t.Errorf("primary = %q\nwant %q", got, want)
+	}
+
+	// 不带副本时读写都走主库
+	db.SetReplicas(RoundRobin)
+	if err := db.Where("id = ?", 1).Find(&out); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(primary.statements()); n != len(want)+1 {
+		t.Errorf("read after removing the replicas did not reach the primary")
+	}
+}
+
+func TestReplicaLeastLatency(t *testing.T) {
+	db, _, reps := replicaDB(t, LeastLatency)
+	p := db.replicas
+	now := time.Now().UnixNano()
+	p.replicas[0].latency, p.replicas[0].sampled = int64(time.Millisecond), now
+	p.replicas[1].latency, p.replicas[1].sampled = int64(50*time.Millisecond), now
+
+	var out []replicaDoc
+	for i := 0; i < 3; i++ {
+		if err := db.Where("id = ?", i).Find(&out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n0, n1 := len(reps[0].statements()), len(reps[1].statements()); n0 != 3 || n1 != 0 {
+		t.Errorf("reads = %d, %d; want all on the faster replica", n0, n1)
+	}
+
+	// 长时间未测量的慢副本被探测一次，随后恢复选择快的副本
+	p.replicas[1].sampled = now - int64(2*replicaProbeInterval)
+	for i := 0; i < 2; i++ {
+		if err := db.Where("id = ?", i).Find(&out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n0, n1 := len(reps[0].statements()), len(reps[1].statements()); n0 != 4 || n1 != 1 {
+		t.Errorf("reads = %d, %d; want one probe of the slow replica", n0, n1)
+	}
+	if p.replicas[1].sampled < now {
+		t.Errorf("probe did not record a new sample for the slow replica")
+	}
+}
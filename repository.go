@@ -0,0 +1,153 @@
+package gom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Repository offers standard CRUD for struct type T, so a table only
+// needs its struct definition. The table comes from getTable and the
+// primary key from the db:"id" tag.
+type Repository[T any] struct {
+	db    *ConDB
+	table string
+	pk    string
+}
+
+// NewRepository creates a repository for T on db.
+func NewRepository[T any](db *ConDB) *Repository[T] {
+	var zero T
+	pk := "id"
+	if t := reflect.TypeOf(zero); t != nil && t.Kind() == reflect.Struct {
+		if _, col, ok := findIDField(reflect.New(t).Elem()); ok {
+			pk = col
+		}
+	}
+	return &Repository[T]{db: db.root(), table: getTable(zero), pk: pk}
+}
+
+// WithTx returns a copy of the repository whose calls run in tx's
+// transaction, tx being a chain from TxBegin or Tx.
+func (r *Repository[T]) WithTx(tx *ConDB) *Repository[T] {
+	c := *r
+	c.db = r.db.Tx(tx.tx)
+	return &c
+}
+
+// Table reports the table the repository works on.
+func (r *Repository[T]) Table() string {
+	return r.table
+}
+
+func (r *Repository[T]) chain(ctx context.Context) *ConDB {
+	db := r.db.clone()
+	db.tx = r.db.tx
+	db.ctx = ctx
+	db.builder.From(r.table)
+	return db
+}
+
+func (r *Repository[T]) where(ctx context.Context, conditions map[string]interface{}) *ConDB {
+	db := r.chain(ctx)
+	keys := make([]string, 0, len(conditions))
+	for k := range conditions {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		db.builder.Where(k+" = ?", conditions[k])
+	}
+	return db
+}
+
+// Create inserts item and fills its auto-increment id.
+func (r *Repository[T]) Create(ctx context.Context, item *T) error {
+	return r.chain(ctx).Insert(item)
+}
+
+// CreateMany inserts items in one transaction, or in the repository's
+// transaction when it has one.
+func (r *Repository[T]) CreateMany(ctx context.Context, items []T) error {
+	if len(items) == 0 {
+		return nil
+	}
+	if r.db.tx != nil {
+		for i := range items {
+			if err := r.chain(ctx).Insert(&items[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	tx, err := r.db.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	txRepo := *r
+	txRepo.db = r.db.Tx(tx)
+	for i := range items {
+		if err := txRepo.chain(ctx).Insert(&items[i]); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetByID loads the row whose primary key is id, or sql.ErrNoRows.
+func (r *Repository[T]) GetByID(ctx context.Context, id interface{}) (T, error) {
+	var out T
+	err := r.chain(ctx).Where(r.pk+" = ?", id).Get(&out)
+	return out, err
+}
+
+// FindBy returns the rows whose columns equal conditions.
+func (r *Repository[T]) FindBy(ctx context.Context, conditions map[string]interface{}) ([]T, error) {
+	var out []T
+	err := r.where(ctx, conditions).Find(&out)
+	return out, err
+}
+
+// Update writes every column of item by its primary key.
+func (r *Repository[T]) Update(ctx context.Context, item *T) error {
+	return r.chain(ctx).Save(item)
+}
+
+// Delete removes the row whose primary key is id. On a sharded table the
+// primary key must be the shard key; otherwise use ConDB.Delete(&obj).
+func (r *Repository[T]) Delete(ctx context.Context, id interface{}) error {
+	if id == nil {
+		return errors.New("gom: Delete needs an id")
+	}
+	var zero T
+	return r.chain(ctx).deleteByID(reflect.TypeOf(zero), r.pk, id)
+}
+
+// Exists reports whether any row matches conditions.
+func (r *Repository[T]) Exists(ctx context.Context, conditions map[string]interface{}) (bool, error) {
+	return r.where(ctx, conditions).IsExit()
+}
+
+// Count returns the number of rows matching conditions.
+func (r *Repository[T]) Count(ctx context.Context, conditions map[string]interface{}) (int64, error) {
+	db := r.where(ctx, conditions)
+	n := db.Count()
+	return n, db.Err
+}
+
+// Paginate returns one page of rows matching conditions, ordered by
+// orderBy (primary key when empty).
+func (r *Repository[T]) Paginate(ctx context.Context, conditions map[string]interface{}, orderBy string, page, size int32) ([]T, Pagination, error) {
+	db := r.where(ctx, conditions)
+	if orderBy == "" {
+		orderBy = fmt.Sprintf("%s ASC", r.pk)
+	}
+	db.OrderBy(orderBy)
+	var out []T
+	p, err := db.Paginate(page, size, &out)
+	return out, p, err
+}
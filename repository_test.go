@@ -0,0 +1,145 @@
+package gom
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type repoDoc struct {
+	Id    int64  `db:"id"`
+	Title string `db:"title"`
+}
+
+func repoDB(t *testing.T) (*Repository[repoDoc], *testDriver) {
+	d := &testDriver{
+		cols:   []string{"id", "title"},
+		tables: map[string][][]driver.Value{getTable(repoDoc{}): {{int64(1), "a"}, {int64(2), "b"}}},
+	}
+	return NewRepository[repoDoc](&ConDB{Db: d.open(t)}), d
+}
+
+func TestRepositoryCRUD(t *testing.T) {
+	repo, d := repoDB(t)
+	ctx := context.Background()
+	table := repo.Table()
+
+	doc := &repoDoc{Title: "x"}
+	if err := repo.Create(ctx, doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Id != 1 {
+		t.Errorf("Create filled id %d, want 1", doc.Id)
+	}
+	got, err := repo.GetByID(ctx, 1)
+	if err != nil || got != (repoDoc{Id: 1, Title: "a"}) {
+		t.Errorf("GetByID = %+v, %v", got, err)
+	}
+	list, err := repo.FindBy(ctx, map[string]interface{}{"title": "a", "id": 1})
+	if err != nil || len(list) != 2 {
+		t.Errorf("FindBy = %+v, %v", list, err)
+	}
+	if err := repo.Update(ctx, &repoDoc{Id: 1, Title: "y"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Delete(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Delete(ctx, nil); err == nil {
+		t.Error("Delete(nil) succeeded")
+	}
+	if n, err := repo.Count(ctx, map[string]interface{}{"title": "a"}); err != nil || n != 2 {
+		t.Errorf("Count = %d, %v", n, err)
+	}
+
+	want := []string{
+		"INSERT INTO " + table + " (title) VALUES (?)",
+		"SELECT * FROM " + table + " WHERE id = ? LIMIT 1",
+		"SELECT * FROM " + table + " WHERE id = ? AND title = ?",
+		"UPDATE " + table + " SET title = ? WHERE id = ?",
+		"DELETE FROM " + table + " WHERE id = ?",
+		"SELECT COUNT(*) FROM " + table + " WHERE title = ?",
+	}
+	if s := d.statements(); !reflect.DeepEqual(s, want) {
+		t.Errorf("statements = %q\nwant %q", s, want)
+	}
+}
+
+func TestRepositoryExists(t *testing.T) {
+	table := getTable(repoDoc{})
+	for _, found := range []bool{false, true} {
+		d := &testDriver{cols: []string{"1"}}
+		if found {
+			d.tables = map[string][][]driver.Value{table: {{int64(1)}}}
+		}
+		repo := NewRepository[repoDoc](&ConDB{Db: d.open(t)})
+		ok, err := repo.Exists(context.Background(), map[string]interface{}{"title": "a"})
+		if err != nil || ok != found {
+			t.Errorf("Exists = %v, %v; want %v", ok, err, found)
+		}
+		if s := d.statements(); !reflect.DeepEqual(s, []string{"SELECT 1 FROM " + table + " WHERE title = ? LIMIT 1"}) {
+			t.Errorf("statements = %q", s)
+		}
+	}
+}
+
+func TestRepositoryCreateMany(t *testing.T) {
+	table := getTable(repoDoc{})
+	insert := "INSERT INTO " + table + " (title) VALUES (?)"
+
+	t.Run("own transaction", func(t *testing.T) {
+		repo, d := repoDB(t)
+		items := []repoDoc{{Title: "a"}, {Title: "b"}}
+		if err := repo.CreateMany(context.Background(), items); err != nil {
+			t.Fatal(err)
+		}
+		if items[0].Id != 1 || items[1].Id != 2 {
+			t.Errorf("ids = %d, %d; want 1, 2", items[0].Id, items[1].Id)
+		}
+		if s := d.statements(); !reflect.DeepEqual(s, []string{"BEGIN", insert, insert, "COMMIT"}) {
+			t.Errorf("statements = %q", s)
+		}
+	})
+
+	t.Run("rollback on error", func(t *testing.T) {
+		repo, d := repoDB(t)
+		boom := errors.New("boom")
+		n := 0
+		repo.db.Use(func(next Handler) Handler {
+			return func(st *Statement) (*Outcome, error) {
+				if n++; n == 2 {
+					return nil, boom
+				}
+				return next(st)
+			}
+		})
+		if err := repo.CreateMany(context.Background(), []repoDoc{{Title: "a"}, {Title: "b"}}); err != boom {
+			t.Fatalf("err = %v, want %v", err, boom)
+		}
+		if s := d.statements(); !reflect.DeepEqual(s, []string{"BEGIN", insert, "ROLLBACK"}) {
+			t.Errorf("statements = %q", s)
+		}
+	})
+
+	t.Run("caller transaction", func(t *testing.T) {
+		repo, d := repoDB(t)
+		tx := repo.db.TxBegin()
+		txRepo := repo.WithTx(tx)
+		if err := txRepo.CreateMany(context.Background(), []repoDoc{{Title: "a"}, {Title: "b"}}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := txRepo.GetByID(context.Background(), 1); err != nil && err != sql.ErrNoRows {
+			t.Fatal(err)
+		}
+		if err := tx.Rollback(); err != nil {
+			t.Fatal(err)
+		}
+		want := []string{"BEGIN", insert, insert, "SELECT * FROM " + table + " WHERE id = ? LIMIT 1", "ROLLBACK"}
+		if s := d.statements(); !reflect.DeepEqual(s, want) {
+			t.Errorf("statements = %q\nwant %q", s, want)
+		}
+	})
+}
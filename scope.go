@@ -0,0 +1,77 @@
+package gom
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Scope is a reusable piece of a chain, e.g. a filter, ordering or page.
+// It receives the chain and returns it, usually after calling Where, Maps,
+// OrderBy or Page on it.
+type Scope func(db *ConDB) *ConDB
+
+var scopeRegistry = struct {
+	sync.RWMutex
+	byTable map[string]map[string]Scope
+}{byTable: make(map[string]map[string]Scope)}
+
+// RegisterScope names a scope for model's table so chains can apply it
+// with Scope(name).
+func RegisterScope(model interface{}, name string, fn Scope) {
+	RegisterTableScope(getTable(model), name, fn)
+}
+
+func RegisterTableScope(table, name string, fn Scope) {
+	scopeRegistry.Lock()
+	defer scopeRegistry.Unlock()
+	scopes := scopeRegistry.byTable[table]
+	if scopes == nil {
+		scopes = make(map[string]Scope)
+		scopeRegistry.byTable[table] = scopes
+	}
+	scopes[name] = fn
+}
+
+func lookupScope(table, name string) Scope {
+	scopeRegistry.RLock()
+	defer scopeRegistry.RUnlock()
+	return scopeRegistry.byTable[table][name]
+}
+
+// Scopes applies fns to the chain in order. Conditions they add are ANDed
+// with the rest of the chain, so a scope needing OR should wrap it in
+// parentheses within a single Where.
+func (m *ConDB) Scopes(fns ...Scope) *ConDB {
+	db := m
+	if m.parent == nil {
+		db = m.clone()
+	}
+	for _, fn := range fns {
+		if next := fn(db); next != nil {
+			db = next
+		}
+	}
+	return db
+}
+
+// Scope applies the scopes registered under names for the chain's table,
+// which must already be set by Model or Table. An unknown name fails the
+// chain.
+func (m *ConDB) Scope(names ...string) *ConDB {
+	db := m
+	if m.parent == nil {
+		db = m.clone()
+	}
+	if db.builder.table == "" {
+		return db.fail(fmt.Errorf("gom: Scope %v needs Model or Table first", names))
+	}
+	fns := make([]Scope, 0, len(names))
+	for _, name := range names {
+		fn := lookupScope(db.builder.table, name)
+		if fn == nil {
+			return db.fail(fmt.Errorf("gom: unknown scope %q for %s", name, db.builder.table))
+		}
+		fns = append(fns, fn)
+	}
+	return db.Scopes(fns...)
+}
@@ -0,0 +1,470 @@
+package gom
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrShardKeyMissing = errors.New("gom: shard key missing from conditions")
+	ErrCrossShard      = errors.New("gom: statement spans more than one shard")
+)
+
+// ShardConfig splits a logical table into Count physical tables.
+type ShardConfig struct {
+	Key   string // 分片键列名，如 user_id
+	Count int
+	// Func maps a shard key value to a shard index in [0, Count).
+	// Nil means integer modulo, or CRC32 modulo for non-numeric keys.
+	Func func(key interface{}) (int, error)
+	// Pattern formats physical names. With a %s it receives the logical
+	// table and the index, otherwise only the index. Default "%s_%02d".
+	Pattern string
+	// FanOut lets reads without the shard key run on every shard and merge
+	// the results; otherwise they fail with ErrShardKeyMissing.
+	FanOut bool
+
+	keyEq, keyIn *regexp.Regexp // 注册时编译，匹配 key = ? 与 key IN (...)
+}
+
+var (
+	shardRegistry sync.Map // logical table -> *ShardConfig
+	shardLogical  sync.Map // physical table -> logical table
+)
+
+// RegisterShard shards model's table, e.g. tb_order into tb_order_00..63.
+func RegisterShard(model interface{}, cfg ShardConfig) {
+	RegisterShardTable(getTable(model), cfg)
+}
+
+func RegisterShardTable(table string, cfg ShardConfig) {
+	if cfg.Pattern == "" {
+		cfg.Pattern = "%s_%02d"
+	}
+	col := "`?" + regexp.QuoteMeta(cfg.Key) + "`?"
+	cfg.keyEq = regexp.MustCompile(`(?i)^\s*` + col + `\s*=\s*\?\s*$`)
+	cfg.keyIn = regexp.MustCompile(`(?i)^\s*` + col + `\s+IN\s*\(`)
+	shardRegistry.Store(table, &cfg)
+	for _, physical := range cfg.all(table) {
+		shardLogical.Store(physical, table)
+	}
+}
+
+func shardConfig(table string) *ShardConfig {
+	if v, ok := shardRegistry.Load(table); ok {
+		return v.(*ShardConfig)
+	}
+	return nil
+}
+
+// logicalTable 将物理分表名还原为逻辑表名；非分表时原样返回，shard 为空
+func logicalTable(table string) (logical, shard string) {
+	if v, ok := shardLogical.Load(table); ok {
+		return v.(string), table
+	}
+	return table, ""
+}
+
+func (c *ShardConfig) physical(table string, idx int) string {
+	if strings.Contains(c.Pattern, "%s") {
+		return fmt.Sprintf(c.Pattern, table, idx)
+	}
+	return fmt.Sprintf(c.Pattern, idx)
+}
+
+func (c *ShardConfig) all(table string) []string {
+	tables := make([]string, c.Count)
+	for i := range tables {
+		tables[i] = c.physical(table, i)
+	}
+	return tables
+}
+
+func (c *ShardConfig) index(v interface{}) (int, error) {
+	if c.Func != nil {
+		idx, err := c.Func(v)
+		if err == nil && (idx < 0 || idx >= c.Count) {
+			err = fmt.Errorf("gom: shard index %d out of range [0,%d)", idx, c.Count)
+		}
+		return idx, err
+	}
+	if c.Count <= 0 {
+		return 0, errors.New("gom: shard count not set")
+	}
+	s := parseString(v)
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if n < 0 {
+			n = -n
+		}
+		return int(n % int64(c.Count)), nil
+	}
+	if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return int(n % uint64(c.Count)), nil
+	}
+	return int(crc32.ChecksumIEEE([]byte(s)) % uint32(c.Count)), nil
+}
+
+func (c *ShardConfig) tablesFor(table string, keys []interface{}) ([]string, error) {
+	seen := make(map[int]bool)
+	var idxs []int
+	for _, k := range keys {
+		idx, err := c.index(k)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[idx] {
+			seen[idx] = true
+			idxs = append(idxs, idx)
+		}
+	}
+	sort.Ints(idxs)
+	tables := make([]string, len(idxs))
+	for i, idx := range idxs {
+		tables[i] = c.physical(table, idx)
+	}
+	return tables, nil
+}
+
+// shardKeyValues 从 "key = ?" 或 "key IN (...)" 条件中提取分片键；含 OR 时无法确定
+func (b *SQLBuilder) shardKeyValues(cfg *ShardConfig) ([]interface{}, bool) {
+	eq, in := cfg.keyEq, cfg.keyIn
+
+	for _, c := range b.clauses {
+		if c.kind == "or" {
+			return nil, false
+		}
+	}
+	for _, c := range b.clauses {
+		switch c.kind {
+		case "where":
+			if len(c.args) == 1 && eq.MatchString(c.expr) {
+				return c.args, true
+			}
+		case "in":
+			if in.MatchString(c.expr) {
+				return c.args, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// routeShard 解析分片表：命中单个分片时直接改写 builder 的表名并返回 nil，
+// 需要跨分片读取时返回全部目标表
+func (m *ConDB) routeShard() ([]string, error) {
+	// 所有基于 builder 的语句都经过这里，先加上租户条件
+	if err := m.applyTenant(); err != nil {
+		return nil, err
+	}
+	table := m.builder.table
+	cfg := shardConfig(table)
+	if cfg == nil {
+		return nil, nil
+	}
+	keys, ok := m.builder.shardKeyValues(cfg)
+	var tables []string
+	if ok {
+		var err error
+		if tables, err = cfg.tablesFor(table, keys); err != nil {
+			return nil, err
+		}
+	} else {
+		if !cfg.FanOut {
+			return nil, ErrShardKeyMissing
+		}
+		tables = cfg.all(table)
+	}
+	if len(tables) == 1 {
+		m.builder.From(tables[0])
+		return nil, nil
+	}
+	if m.builder.groupBy != "" {
+		return nil, errors.New("gom: GROUP BY cannot fan out across shards")
+	}
+	return tables, nil
+}
+
+// routeShardSingle 用于写入及无法合并的读取，必须落在单个分片
+func (m *ConDB) routeShardSingle() error {
+	table := m.builder.table
+	tables, err := m.routeShard()
+	if err != nil {
+		return err
+	}
+	if len(tables) > 0 {
+		if _, ok := m.builder.shardKeyValues(shardConfig(table)); !ok {
+			return ErrShardKeyMissing
+		}
+		return ErrCrossShard
+	}
+	return nil
+}
+
+// shardTableOf 返回插入对象所在的物理表
+func shardTableOf(table string, obj interface{}) (string, error) {
+	cfg := shardConfig(table)
+	if cfg == nil {
+		return table, nil
+	}
+	v := reflect.Indirect(reflect.ValueOf(obj))
+	idx, ok := getFieldMap(v.Type())[strings.ToLower(cfg.Key)]
+	if !ok {
+		return "", ErrShardKeyMissing
+	}
+	tables, err := cfg.tablesFor(table, []interface{}{v.FieldByIndex(idx.Index).Interface()})
+	if err != nil {
+		return "", err
+	}
+	return tables[0], nil
+}
+
+// shardBuilder 复制 builder 到某个分片，LIMIT 扩大为 offset+count 以便合并后再截取
+func (m *ConDB) shardBuilder(table string) *SQLBuilder {
+	b := *m.builder
+	b.table = table
+	if b.limited {
+		b.count += b.offset
+		b.offset = 0
+	}
+	return &b
+}
+
+func (m *ConDB) fanOutFind(tables []string, out interface{}) error {
+	sliceValue := reflect.ValueOf(out).Elem()
+	all := reflect.MakeSlice(sliceValue.Type(), 0, 0)
+
+	for _, t := range tables {
+		sqlStr, args := m.shardBuilder(t).Build()
+		m.trace(sqlStr, args...)
+
+		rows, err := m.query(StmtSelect, t, sqlStr, args)
+		if err != nil {
+			return err
+		}
+		part := reflect.New(sliceValue.Type())
+		err = RowsToList(rows, part.Interface())
+		rows.Close()
+		if err != nil {
+			return err
+		}
+		all = reflect.AppendSlice(all, part.Elem())
+	}
+
+	keys := parseOrderBy(m.builder.orderBy)
+	if len(keys) > 0 {
+		fieldMap := getFieldMap(sliceValue.Type().Elem())
+		sort.SliceStable(all.Interface(), func(i, j int) bool {
+			a, b := all.Index(i), all.Index(j)
+			for _, k := range keys {
+				idx, ok := fieldMap[strings.ToLower(k.column)]
+				if !ok {
+					continue
+				}
+				c := compareValues(a.FieldByIndex(idx.Index).Interface(), b.FieldByIndex(idx.Index).Interface())
+				if c != 0 {
+					return (c < 0) != k.desc
+				}
+			}
+			return false
+		})
+	}
+
+	lo, hi := m.window(all.Len())
+	sliceValue.Set(reflect.AppendSlice(sliceValue, all.Slice(lo, hi)))
+	return nil
+}
+
+func (m *ConDB) fanOutList(tables []string) ([]map[string]interface{}, error) {
+	var all []map[string]interface{}
+	for _, t := range tables {
+		sqlStr, args := m.shardBuilder(t).Build()
+		m.trace(sqlStr, args...)
+
+		rows, err := m.query(StmtSelect, t, sqlStr, args)
+		if err != nil {
+			return nil, err
+		}
+		part, err := RowsToMaps(rows)
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, part...)
+	}
+
+	keys := parseOrderBy(m.builder.orderBy)
+	if len(keys) > 0 {
+		sort.SliceStable(all, func(i, j int) bool {
+			for _, k := range keys {
+				c := compareValues(all[i][k.column], all[j][k.column])
+				if c != 0 {
+					return (c < 0) != k.desc
+				}
+			}
+			return false
+		})
+	}
+
+	lo, hi := m.window(len(all))
+	return all[lo:hi], nil
+}
+
+func (m *ConDB) fanOutCount(tables []string, field string) (int64, error) {
+	var total int64
+	for _, t := range tables {
+		b := m.shardBuilder(t)
+		b.fields = "COUNT(" + field + ")"
+		b.orderBy = ""
+		b.limited = false
+		sqlStr, args := b.Build()
+		m.trace(sqlStr, args...)
+
+		rows, err := m.query(StmtSelect, t, sqlStr, args)
+		if err != nil {
+			return 0, err
+		}
+		var n int64
+		if err := scanOne(rows, &n); err != nil {
+			return 0, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// window 返回合并结果中 LIMIT/OFFSET 对应的区间
+func (m *ConDB) window(n int) (int, int) {
+	if !m.builder.limited {
+		return 0, n
+	}
+	lo := int(m.builder.offset)
+	if lo > n {
+		lo = n
+	}
+	hi := lo + int(m.builder.count)
+	if hi > n {
+		hi = n
+	}
+	return lo, hi
+}
+
+type orderKey struct {
+	column string
+	desc   bool
+}
+
+func parseOrderBy(orderBy string) []orderKey {
+	var keys []orderKey
+	for _, part := range strings.Split(orderBy, ",") {
+		f := strings.Fields(part)
+		if len(f) == 0 {
+			continue
+		}
+		k := orderKey{column: strings.Trim(f[0], "`")}
+		if i := strings.LastIndex(k.column, "."); i >= 0 {
+			k.column = k.column[i+1:]
+		}
+		if len(f) > 1 && strings.EqualFold(f[1], "desc") {
+			k.desc = true
+		}
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// compareValues 比较两个列值，NULL 最小
+func compareValues(a, b interface{}) int {
+	if a == nil || b == nil {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return -1
+		default:
+			return 1
+		}
+	}
+	if ta, ok := a.(time.Time); ok {
+		if tb, ok := b.(time.Time); ok {
+			switch {
+			case ta.Before(tb):
+				return -1
+			case ta.After(tb):
+				return 1
+			}
+			return 0
+		}
+	}
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch va.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if vb.Kind() >= reflect.Int && vb.Kind() <= reflect.Int64 {
+			return cmpInt(va.Int(), vb.Int())
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if vb.Kind() >= reflect.Uint && vb.Kind() <= reflect.Uint64 {
+			x, y := va.Uint(), vb.Uint()
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	case reflect.Float32, reflect.Float64:
+		if vb.Kind() == reflect.Float32 || vb.Kind() == reflect.Float64 {
+			x, y := va.Float(), vb.Float()
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	case reflect.Bool:
+		if vb.Kind() == reflect.Bool {
+			return cmpInt(boolInt(va.Bool()), boolInt(vb.Bool()))
+		}
+	}
+	// 类型不一致时尝试按数值比较，否则按字符串
+	sa, sb := parseString(a), parseString(b)
+	if fa, err := strconv.ParseFloat(sa, 64); err == nil {
+		if fb, err := strconv.ParseFloat(sb, 64); err == nil {
+			switch {
+			case fa < fb:
+				return -1
+			case fa > fb:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(sa, sb)
+}
+
+func cmpInt(x, y int64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+func boolInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,169 @@
+package gom
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type fanOrder struct {
+	Id     int64  `db:"id"`
+	UserId int64  `db:"user_id"`
+	Note   string `db:"note"`
+}
+
+func fanOutDB(t *testing.T) (*ConDB, *testDriver) {
+	RegisterShardTable("tb_fan_order", ShardConfig{Key: "user_id", Count: 3, FanOut: true})
+	d := &testDriver{
+		cols: []string{"id", "user_id", "note"},
+		tables: map[string][][]driver.Value{
+			"tb_fan_order_00": {{int64(9), int64(3), "c"}, {int64(3), int64(6), "a"}},
+			"tb_fan_order_01": {{int64(7), int64(1), "b"}, {int64(1), int64(4), "a"}},
+			"tb_fan_order_02": {{int64(8), int64(2), "b"}, {int64(2), int64(5), "c"}},
+		},
+	}
+	return &ConDB{Db: d.open(t)}, d
+}
+
+func TestShardWindow(t *testing.T) {
+	tests := []struct {
+		name          string
+		limit         bool
+		offset, count int32
+		n             int
+		lo, hi        int
+	}{
+		{"no limit", false, 0, 0, 5, 0, 5},
+		{"first page", true, 0, 2, 5, 0, 2},
+		{"middle page", true, 2, 2, 5, 2, 4},
+		{"last partial page", true, 4, 2, 5, 4, 5},
+		{"past the end", true, 8, 2, 5, 5, 5},
+		{"empty", true, 0, 10, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := (&ConDB{}).DryRun().Table("tb_x")
+			if tt.limit {
+				db = db.Limit(tt.offset, tt.count)
+			}
+			if lo, hi := db.window(tt.n); lo != tt.lo || hi != tt.hi {
+				t.Errorf("window(%d) = %d, %d; want %d, %d", tt.n, lo, hi, tt.lo, tt.hi)
+			}
+		})
+	}
+}
+
+func TestFanOutFind(t *testing.T) {
+	tests := []struct {
+		name   string
+		order  string
+		offset int32
+		count  int32
+		want   []int64
+	}{
+		{"id desc", "id desc", 0, 0, []int64{9, 8, 7, 3, 2, 1}},
+		{"id asc page", "id", 1, 3, []int64{2, 3, 7}},
+		{"two keys", "note asc, id desc", 0, 4, []int64{3, 1, 8, 7}},
+		{"past the end", "id", 10, 2, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, d := fanOutDB(t)
+			q := db.Table("tb_fan_order").OrderBy(tt.order)
+			if tt.count > 0 {
+				q = q.Limit(tt.offset, tt.count)
+			}
+			var out []fanOrder
+			if err := q.Find(&out); err != nil {
+				t.Fatal(err)
+			}
+			var ids []int64
+			for _, o := range out {
+				ids = append(ids, o.Id)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("ids = %v, want %v", ids, tt.want)
+			}
+
+			stmts := d.statements()
+			if len(stmts) != 3 {
+				t.Fatalf("got %d shard statements, want 3: %q", len(stmts), stmts)
+			}
+			// 每个分片取 offset+count 行，不带 OFFSET
+			for _, s := range stmts {
+				if tt.count > 0 && !strings.HasSuffix(s, " LIMIT "+strconv.Itoa(int(tt.offset+tt.count))+" OFFSET 0") {
+					t.Errorf("shard statement %q does not widen the limit", s)
+				}
+			}
+		})
+	}
+}
+
+func TestFanOutListAndCount(t *testing.T) {
+	db, _ := fanOutDB(t)
+	rows, err := db.Table("tb_fan_order").OrderBy("id desc").Limit(0, 2).List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || compareValues(rows[0]["id"], 9) != 0 || compareValues(rows[1]["id"], 8) != 0 {
+		t.Errorf("List = %v, want ids 9, 8", rows)
+	}
+	if n := db.Table("tb_fan_order").Count(); n != 6 {
+		t.Errorf("Count = %d, want 6", n)
+	}
+}
+
+func TestShardRouting(t *testing.T) {
+	db, d := fanOutDB(t)
+	var out []fanOrder
+	if err := db.Table("tb_fan_order").Where("user_id = ?", 4).Find(&out); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Table("tb_fan_order").In("user_id", []interface{}{3, 6}).Find(&out); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"SELECT * FROM tb_fan_order_01 WHERE user_id = ?", "SELECT * FROM tb_fan_order_00 WHERE user_id IN (?,?)"}
+	if got := d.statements(); !reflect.DeepEqual(got, want) {
+		t.Errorf("statements = %q, want %q", got, want)
+	}
+	if err := db.Table("tb_fan_order").Where("user_id = ?", 1).Or("id = ?", 2).Delete(); err != ErrShardKeyMissing {
+		t.Errorf("Delete with OR: err = %v, want ErrShardKeyMissing", err)
+	}
+}
+
+func TestShardStatementTable(t *testing.T) {
+	db, _ := fanOutDB(t)
+	var got []RecordedStatement
+	db.Use(func(next Handler) Handler {
+		return func(st *Statement) (*Outcome, error) {
+			got = append(got, RecordedStatement{Kind: st.Kind, Table: st.Table, Shard: st.Shard})
+			return next(st)
+		}
+	})
+	var out []fanOrder
+	if err := db.Table("tb_fan_order").Where("user_id = ?", 4).Find(&out); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Table("tb_fan_order").Find(&out); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Table("tb_fan_order").Insert(&fanOrder{UserId: 5}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Table("tb_plain").Where("id = ?", 1).Delete(); err != nil {
+		t.Fatal(err)
+	}
+	want := []RecordedStatement{
+		{Kind: StmtSelect, Table: "tb_fan_order", Shard: "tb_fan_order_01"},
+		{Kind: StmtSelect, Table: "tb_fan_order", Shard: "tb_fan_order_00"},
+		{Kind: StmtSelect, Table: "tb_fan_order", Shard: "tb_fan_order_01"},
+		{Kind: StmtSelect, Table: "tb_fan_order", Shard: "tb_fan_order_02"},
+		{Kind: StmtInsert, Table: "tb_fan_order", Shard: "tb_fan_order_02"},
+		{Kind: StmtDelete, Table: "tb_plain"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("statements = %+v\nwant %+v", got, want)
+	}
+}
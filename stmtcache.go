@@ -0,0 +1,193 @@
+package gom
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// StmtCacheStats reports prepared statement cache usage.
+type StmtCacheStats struct {
+	Size      int
+	Capacity  int
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+// HitRatio is Hits / (Hits + Misses), 0 before the first lookup.
+func (s StmtCacheStats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+type stmtKey struct {
+	db    *sql.DB
+	query string
+}
+
+type stmtEntry struct {
+	key     stmtKey
+	stmt    *sql.Stmt
+	refs    int  // 正在使用该语句的调用数
+	evicted bool // 已移出缓存，最后一个使用者释放时关闭
+}
+
+// stmtCache 是按 SQL 文本缓存 *sql.Stmt 的 LRU
+type stmtCache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[stmtKey]*list.Element
+	stats    StmtCacheStats
+}
+
+func newStmtCache(capacity int) *stmtCache {
+	return &stmtCache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[stmtKey]*list.Element),
+	}
+}
+
+// get 取出语句并占用，用完后必须调用 release
+func (c *stmtCache) get(ctx context.Context, db *sql.DB, query string) (*stmtEntry, error) {
+	key := stmtKey{db, query}
+
+	c.mu.Lock()
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		c.stats.Hits++
+		e := el.Value.(*stmtEntry)
+		e.refs++
+		c.mu.Unlock()
+		return e, nil
+	}
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// 并发准备了同一条语句时保留先入缓存的那个
+	if el, ok := c.items[key]; ok {
+		stmt.Close()
+		c.ll.MoveToFront(el)
+		e := el.Value.(*stmtEntry)
+		e.refs++
+		return e, nil
+	}
+	e := &stmtEntry{key: key, stmt: stmt, refs: 1}
+	c.items[key] = c.ll.PushFront(e)
+	for c.ll.Len() > c.capacity {
+		c.removeElement(c.ll.Back())
+		c.stats.Evictions++
+	}
+	return e, nil
+}
+
+// release 归还 get 占用的语句；已淘汰且无人使用时关闭。
+// 已返回的 Rows 不受影响，database/sql 会在其关闭后再释放底层语句
+func (c *stmtCache) release(e *stmtEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e.refs--
+	if e.evicted && e.refs == 0 {
+		e.stmt.Close()
+	}
+}
+
+func (c *stmtCache) removeElement(el *list.Element) {
+	c.ll.Remove(el)
+	e := el.Value.(*stmtEntry)
+	delete(c.items, e.key)
+	e.evicted = true
+	if e.refs == 0 {
+		e.stmt.Close()
+	}
+}
+
+func (c *stmtCache) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.ll.Len() > 0 {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+func (c *stmtCache) snapshot() StmtCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.Size = c.ll.Len()
+	s.Capacity = c.capacity
+	return s
+}
+
+// EnableStmtCache keeps up to size prepared statements on the root ConDB,
+// keyed by SQL text. Statements run inside a transaction are rebound with
+// tx.Stmt. A size <= 0 disables the cache and closes cached statements.
+func (m *ConDB) EnableStmtCache(size int) *ConDB {
+	root := m.root()
+	if root.stmts != nil {
+		root.stmts.purge()
+		root.stmts = nil
+	}
+	if size > 0 {
+		root.stmts = newStmtCache(size)
+	}
+	return m
+}
+
+// StmtCacheStats returns the cache counters; zero when the cache is off.
+func (m *ConDB) StmtCacheStats() StmtCacheStats {
+	root := m.root()
+	if root.stmts == nil {
+		return StmtCacheStats{}
+	}
+	return root.stmts.snapshot()
+}
+
+// executeCached 通过缓存的预处理语句执行；无法缓存的执行器直接执行
+func (m *ConDB) executeCached(st *Statement) (*Outcome, error) {
+	var db *sql.DB
+	switch ex := st.Executor.(type) {
+	case *sql.DB:
+		db = ex
+	case *sql.Tx:
+		db = m.Db
+	default:
+		return execute(st)
+	}
+
+	e, err := m.stmts.get(st.Ctx, db, st.SQL)
+	if err != nil {
+		return nil, err
+	}
+	defer m.stmts.release(e)
+
+	stmt := e.stmt
+	if tx, ok := st.Executor.(*sql.Tx); ok {
+		// 事务结束时自动关闭
+		stmt = tx.StmtContext(st.Ctx, stmt)
+	}
+
+	switch st.Mode {
+	case ModeQuery:
+		rows, err := stmt.QueryContext(st.Ctx, st.Args...)
+		return &Outcome{Rows: rows}, err
+	case ModeQueryRow:
+		return &Outcome{Row: stmt.QueryRowContext(st.Ctx, st.Args...)}, nil
+	default:
+		res, err := stmt.ExecContext(st.Ctx, st.Args...)
+		return &Outcome{Result: res}, err
+	}
+}
@@ -0,0 +1,132 @@
+package gom
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+type cacheRow struct {
+	Id int64 `db:"id"`
+}
+
+func TestStmtCacheStats(t *testing.T) {
+	d := &testDriver{cols: []string{"id"}}
+	db := &ConDB{Db: d.open(t)}
+	db.EnableStmtCache(2)
+
+	var out []cacheRow
+	for _, table := range []string{"tb_a", "tb_b", "tb_a", "tb_c", "tb_b", "tb_a"} {
+		if err := db.Table(table).Where("id = ?", 1).Find(&out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a b 未命中；a 命中；c 未命中并淘汰 b；b 未命中并淘汰 a；a 未命中并淘汰 c
+	want := StmtCacheStats{Size: 2, Capacity: 2, Hits: 1, Misses: 5, Evictions: 3}
+	if got := db.StmtCacheStats(); got != want {
+		t.Errorf("stats = %+v, want %+v", got, want)
+	}
+	if d.prepared != 5 || d.closed != 3 {
+		t.Errorf("prepared %d, closed %d; want 5, 3", d.prepared, d.closed)
+	}
+
+	db.EnableStmtCache(0)
+	if d.closed != 5 {
+		t.Errorf("closed %d after disabling the cache, want 5", d.closed)
+	}
+	if got := db.StmtCacheStats(); got != (StmtCacheStats{}) {
+		t.Errorf("stats with the cache off = %+v", got)
+	}
+}
+
+func TestStmtCacheEvictInUse(t *testing.T) {
+	d := &testDriver{}
+	db := d.open(t)
+	c := newStmtCache(1)
+	ctx := context.Background()
+
+	a, err := c.get(ctx, db, "UPDATE tb_a SET n = 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := c.get(ctx, db, "UPDATE tb_b SET n = 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a 已被淘汰但仍在使用，不能关闭
+	if d.closed != 0 {
+		t.Fatalf("evicted statement closed while in use")
+	}
+	if _, err := a.stmt.Exec(); err != nil {
+		t.Fatalf("exec on the evicted statement: %v", err)
+	}
+	c.release(a)
+	if d.closed != 1 {
+		t.Errorf("closed %d after releasing the evicted statement, want 1", d.closed)
+	}
+	c.release(b)
+	if d.closed != 1 {
+		t.Errorf("cached statement closed on release")
+	}
+}
+
+func TestStmtCacheEvictWithOpenRows(t *testing.T) {
+	d := &testDriver{
+		cols:   []string{"id"},
+		tables: map[string][][]driver.Value{"tb_a": {{int64(1)}, {int64(2)}}},
+	}
+	db := &ConDB{Db: d.open(t)}
+	db.EnableStmtCache(1)
+
+	var row cacheRow
+	it, err := db.Table("tb_a").Rows(&row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 游标打开期间语句被淘汰，底层语句在游标关闭后才释放
+	var out []cacheRow
+	if err := db.Table("tb_b").Find(&out); err != nil {
+		t.Fatal(err)
+	}
+	if d.closed != 0 {
+		t.Fatalf("statement closed under open rows")
+	}
+	n := 0
+	for it.Next() {
+		if err := it.Scan(); err != nil {
+			t.Fatal(err)
+		}
+		n++
+	}
+	if err := it.Err(); err != nil || n != 2 {
+		t.Errorf("read %d rows, err %v; want 2 rows", n, err)
+	}
+	it.Close()
+	if d.closed != 1 {
+		t.Errorf("closed %d after closing the rows, want 1", d.closed)
+	}
+}
+
+func TestStmtCacheTx(t *testing.T) {
+	d := &testDriver{}
+	db := &ConDB{Db: d.open(t)}
+	db.EnableStmtCache(4)
+
+	for i := 0; i < 2; i++ {
+		tx := db.TxBegin()
+		if err := tx.Table("tb_a").Where("id = ?", 1).Update("n = ?", 2); err != nil {
+			t.Fatal(err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s := db.StmtCacheStats(); s.Hits != 1 || s.Misses != 1 {
+		t.Errorf("stats = %+v, want the transaction to reuse the cached statement", s)
+	}
+	want := []string{"BEGIN", "UPDATE tb_a SET n = ? WHERE id = ?", "COMMIT", "BEGIN", "UPDATE tb_a SET n = ? WHERE id = ?", "COMMIT"}
+	if s := d.statements(); !reflect.DeepEqual(s, want) {
+		t.Errorf("statements = %q, want %q", s, want)
+	}
+}
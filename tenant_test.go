@@ -0,0 +1,183 @@
+package gom
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type tenantDoc struct {
+	Id       int64  `db:"id"`
+	TenantId int64  `db:"tenant_id"`
+	Title    string `db:"title"`
+}
+
+func init() {
+	RegisterTenant(tenantDoc{}, "tenant_id")
+}
+
+func TestTenantScoping(t *testing.T) {
+	table := getTable(tenantDoc{})
+	tests := []struct {
+		name string
+		run  func(db *ConDB) error
+		sql  string
+		args []interface{}
+	}{
+		{
+			"find",
+			func(db *ConDB) error { var out []tenantDoc; return db.Tenant(7).Where("title = ?", "x").Find(&out) },
+			"SELECT * FROM " + table + " WHERE title = ? AND tenant_id = ?",
+			[]interface{}{"x", 7},
+		},
+		{
+			"find with or",
+			func(db *ConDB) error {
+				var out []tenantDoc
+				return db.Tenant(7).Where("title = ?", "x").Or("title = ?", "y").Find(&out)
+			},
+			"SELECT * FROM " + table + " WHERE (title = ? OR title = ?) AND tenant_id = ?",
+			[]interface{}{"x", "y", 7},
+		},
+		{
+			"count with or",
+			func(db *ConDB) error {
+				db = db.Tenant(7).Where("title = ?", "x").Or("title = ?", "y")
+				db.Count()
+				return db.Err
+			},
+			"SELECT COUNT(*) FROM " + table + " WHERE (title = ? OR title = ?) AND tenant_id = ?",
+			[]interface{}{"x", "y", 7},
+		},
+		{
+			"update map with or",
+			func(db *ConDB) error {
+				return db.Tenant(7).Where("title = ?", "x").Or("title = ?", "y").UpdateMap(map[string]interface{}{"title": "z"})
+			},
+			"UPDATE " + table + " SET title = ? WHERE (title = ? OR title = ?) AND tenant_id = ?",
+			[]interface{}{"z", "x", "y", 7},
+		},
+		{
+			"update with or",
+			func(db *ConDB) error {
+				return db.Tenant(7).Where("title = ?", "x").Or("title = ?", "y").Update("title = ?", "z")
+			},
+			"UPDATE " + table + " SET title = ? WHERE (title = ? OR title = ?) AND tenant_id = ?",
+			[]interface{}{"z", "x", "y", 7},
+		},
+		{
+			"delete with or",
+			func(db *ConDB) error {
+				return db.Tenant(7).Where("title = ?", "x").Or("title = ?", "y").Delete()
+			},
+			"DELETE FROM " + table + " WHERE (title = ? OR title = ?) AND tenant_id = ?",
+			[]interface{}{"x", "y", 7},
+		},
+		{
+			"context tenant",
+			func(db *ConDB) error {
+				var out []tenantDoc
+				return db.WithContext(WithTenant(context.Background(), 3)).Find(&out)
+			},
+			"SELECT * FROM " + table + " WHERE tenant_id = ?",
+			[]interface{}{3},
+		},
+		{
+			"skip tenant",
+			func(db *ConDB) error { var out []tenantDoc; return db.SkipTenant().Where("id = ?", 1).Find(&out) },
+			"SELECT * FROM " + table + " WHERE id = ?",
+			[]interface{}{1},
+		},
+		{
+			"insert fills tenant",
+			func(db *ConDB) error { return db.Tenant(7).Insert(&tenantDoc{Title: "x"}) },
+			"INSERT INTO " + table + " (tenant_id,title) VALUES (?,?)",
+			[]interface{}{int64(7), "x"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := (&ConDB{}).Model(tenantDoc{}).ToSQL(tt.run)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sql != tt.sql || !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("got %q %v\nwant %q %v", sql, args, tt.sql, tt.args)
+			}
+		})
+	}
+}
+
+func TestTenantRequired(t *testing.T) {
+	dry := (&ConDB{}).DryRun()
+	var out []tenantDoc
+	if err := dry.Model(tenantDoc{}).Find(&out); !errors.Is(err, ErrNoTenant) {
+		t.Errorf("Find without tenant: err = %v, want ErrNoTenant", err)
+	}
+	if err := dry.Insert(&tenantDoc{Title: "x"}); !errors.Is(err, ErrNoTenant) {
+		t.Errorf("Insert without tenant: err = %v, want ErrNoTenant", err)
+	}
+	err := dry.Tenant(7).Insert(&tenantDoc{TenantId: 8})
+	if err == nil || !strings.Contains(err.Error(), "scoped to 7") {
+		t.Errorf("Insert for another tenant: err = %v", err)
+	}
+	if n := len(dry.Statements()); n != 0 {
+		t.Errorf("%d statements were sent without a valid tenant", n)
+	}
+}
+
+func TestTenantAudit(t *testing.T) {
+	table := getTable(tenantDoc{})
+	root := &ConDB{}
+	root.EnableAudit(func(*ConDB, *AuditEntry) error { return nil }, table)
+	ctx := WithTenant(context.Background(), 7)
+
+	tests := []struct {
+		name  string
+		run   func(db *ConDB) error
+		prior string
+	}{
+		{
+			"save with tenant",
+			func(db *ConDB) error { return db.Tenant(7).Model(tenantDoc{}).Save(&tenantDoc{Id: 1, TenantId: 7}) },
+			"SELECT * FROM " + table + " WHERE id = ? AND tenant_id = ? for update",
+		},
+		{
+			"save skipping tenant",
+			func(db *ConDB) error { return db.SkipTenant().Model(tenantDoc{}).Save(&tenantDoc{Id: 1}) },
+			"SELECT * FROM " + table + " WHERE id = ? for update",
+		},
+		{
+			"delete with tenant",
+			func(db *ConDB) error { return db.Tenant(7).Delete(&tenantDoc{Id: 1}) },
+			"SELECT * FROM " + table + " WHERE id = ? AND tenant_id = ? for update",
+		},
+		{
+			"repository update",
+			func(db *ConDB) error { return NewRepository[tenantDoc](db).Update(ctx, &tenantDoc{Id: 1, TenantId: 7}) },
+			"SELECT * FROM " + table + " WHERE id = ? AND tenant_id = ? for update",
+		},
+		{
+			"repository delete",
+			func(db *ConDB) error { return NewRepository[tenantDoc](db).Delete(ctx, 1) },
+			"SELECT * FROM " + table + " WHERE id = ? AND tenant_id = ? for update",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dry := root.DryRun()
+			if err := tt.run(dry); err != nil {
+				t.Fatal(err)
+			}
+			stmts := dry.Statements()
+			if len(stmts) != 2 {
+				t.Fatalf("got %d statements, want the prior row and the write", len(stmts))
+			}
+			if stmts[0].SQL != tt.prior {
+				t.Errorf("prior row query = %q, want %q", stmts[0].SQL, tt.prior)
+			}
+		})
+	}
+}
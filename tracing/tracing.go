@@ -0,0 +1,120 @@
+// Package tracing emits a span per gom statement through a minimal Tracer
+// interface, so any tracing backend can be adapted without gom importing it.
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gkyh/gom"
+)
+
+// Attribute keys follow the OpenTelemetry database semantic conventions.
+const (
+	AttrSystem    = "db.system"
+	AttrStatement = "db.statement"
+	AttrTable     = "db.sql.table"
+	AttrOperation = "db.operation"
+)
+
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// Interceptor starts a span around every statement. system is reported as
+// db.system, e.g. "mysql". The span's context is handed down the chain.
+func Interceptor(tracer Tracer, system string) gom.Interceptor {
+	return func(next gom.Handler) gom.Handler {
+		return func(st *gom.Statement) (*gom.Outcome, error) {
+			name := string(st.Kind)
+			if st.Table != "" {
+				name += " " + st.Table
+			}
+			ctx, span := tracer.Start(st.Ctx, name)
+			defer span.End()
+
+			span.SetAttribute(AttrSystem, system)
+			span.SetAttribute(AttrStatement, st.SQL)
+			span.SetAttribute(AttrOperation, string(st.Kind))
+			if st.Table != "" {
+				span.SetAttribute(AttrTable, st.Table)
+			}
+
+			st.Ctx = ctx
+			out, err := next(st)
+			if err != nil {
+				span.RecordError(err)
+			}
+			return out, err
+		}
+	}
+}
+
+// RecordedSpan is a finished span kept by Recorder.
+type RecordedSpan struct {
+	Name       string
+	Attributes map[string]interface{}
+	Err        error
+	Start      time.Time
+	End        time.Time
+}
+
+// Recorder is an in-memory Tracer, useful in tests.
+type Recorder struct {
+	mu    sync.Mutex
+	spans []RecordedSpan
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+func (r *Recorder) Start(ctx context.Context, name string) (context.Context, Span) {
+	return ctx, &recordedSpan{
+		rec:  r,
+		span: RecordedSpan{Name: name, Attributes: make(map[string]interface{}), Start: time.Now()},
+	}
+}
+
+// Spans returns a copy of the finished spans in completion order.
+func (r *Recorder) Spans() []RecordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RecordedSpan{}, r.spans...)
+}
+
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	r.spans = nil
+	r.mu.Unlock()
+}
+
+type recordedSpan struct {
+	rec  *Recorder
+	span RecordedSpan
+	once sync.Once
+}
+
+func (s *recordedSpan) SetAttribute(key string, value interface{}) {
+	s.span.Attributes[key] = value
+}
+
+func (s *recordedSpan) RecordError(err error) {
+	s.span.Err = err
+}
+
+func (s *recordedSpan) End() {
+	s.once.Do(func() {
+		s.span.End = time.Now()
+		s.rec.mu.Lock()
+		s.rec.spans = append(s.rec.spans, s.span)
+		s.rec.mu.Unlock()
+	})
+}
@@ -0,0 +1,119 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gkyh/gom"
+)
+
+func TestInterceptor(t *testing.T) {
+	boom := errors.New("boom")
+	tests := []struct {
+		name  string
+		st    gom.Statement
+		err   error
+		span  string
+		attrs map[string]interface{}
+	}{
+		{
+			name: "select",
+			st:   gom.Statement{Kind: gom.StmtSelect, Table: "tb_user", SQL: "SELECT * FROM tb_user"},
+			span: "select tb_user",
+			attrs: map[string]interface{}{
+				AttrSystem: "mysql", AttrStatement: "SELECT * FROM tb_user", AttrOperation: "select", AttrTable: "tb_user",
+			},
+		},
+		{
+			name: "raw without table",
+			st:   gom.Statement{Kind: gom.StmtOther, SQL: "SET NAMES utf8mb4"},
+			span: "other",
+			attrs: map[string]interface{}{
+				AttrSystem: "mysql", AttrStatement: "SET NAMES utf8mb4", AttrOperation: "other",
+			},
+		},
+		{
+			name: "error",
+			st:   gom.Statement{Kind: gom.StmtDelete, Table: "tb_user", SQL: "DELETE FROM tb_user"},
+			err:  boom,
+			span: "delete tb_user",
+			attrs: map[string]interface{}{
+				AttrSystem: "mysql", AttrStatement: "DELETE FROM tb_user", AttrOperation: "delete", AttrTable: "tb_user",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := NewRecorder()
+			h := Interceptor(rec, "mysql")(func(st *gom.Statement) (*gom.Outcome, error) {
+				return &gom.Outcome{}, tt.err
+			})
+			st := tt.st
+			st.Ctx = context.Background()
+			if _, err := h(&st); err != tt.err {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+
+			spans := rec.Spans()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+			s := spans[0]
+			if s.Name != tt.span {
+				t.Errorf("name = %q, want %q", s.Name, tt.span)
+			}
+			if s.Err != tt.err {
+				t.Errorf("span err = %v, want %v", s.Err, tt.err)
+			}
+			if len(s.Attributes) != len(tt.attrs) {
+				t.Errorf("attributes = %v, want %v", s.Attributes, tt.attrs)
+			}
+			for k, v := range tt.attrs {
+				if s.Attributes[k] != v {
+					t.Errorf("attribute %s = %v, want %v", k, s.Attributes[k], v)
+				}
+			}
+			if s.End.Before(s.Start) {
+				t.Errorf("span ended before it started")
+			}
+		})
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	rec := NewRecorder()
+	_, a := rec.Start(context.Background(), "a")
+	_, b := rec.Start(context.Background(), "b")
+	b.End()
+	a.End()
+	a.End() // 重复 End 只记录一次
+
+	spans := rec.Spans()
+	if len(spans) != 2 || spans[0].Name != "b" || spans[1].Name != "a" {
+		t.Fatalf("spans = %v, want b then a", spans)
+	}
+	spans[0].Name = "changed"
+	if rec.Spans()[0].Name != "b" {
+		t.Errorf("Spans returned the recorder's own slice")
+	}
+	rec.Reset()
+	if len(rec.Spans()) != 0 {
+		t.Errorf("Reset kept spans")
+	}
+}
+
+func TestInterceptorOnDryRun(t *testing.T) {
+	rec := NewRecorder()
+	db := (&gom.ConDB{}).DryRun()
+	db.Use(Interceptor(rec, "mysql"))
+	db.Table("tb_order").Where("id = ?", 1).Delete()
+
+	spans := rec.Spans()
+	if len(spans) != 1 || spans[0].Name != "delete tb_order" {
+		t.Fatalf("spans = %v, want one delete tb_order", spans)
+	}
+	if got := spans[0].Attributes[AttrStatement]; got != "DELETE FROM tb_order WHERE id = ?" {
+		t.Errorf("statement = %v", got)
+	}
+}
@@ -11,14 +11,18 @@ func (db *ConDB) GetForUpdate(out interface{}) error {
 
 		db.builder.From(getTable(out))
 	}
+	if err := db.routeShardSingle(); err != nil {
+		return err
+	}
 
 	sqlStr, args := db.builder.Build()
 
 	sqlStr += " for update"
+	db.primary = true
 
 	db.trace(sqlStr)
 
-	rows, err := db.tx.Query(sqlStr, args...)
+	rows, err := db.query(StmtSelect, db.builder.table, sqlStr, args)
 	if err != nil {
 
 		return err
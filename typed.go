@@ -0,0 +1,160 @@
+package gom
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// TypedQuery is a chain bound to struct type T, returning T values instead
+// of filling interface{} arguments.
+type TypedQuery[T any] struct {
+	db  *ConDB
+	err error
+}
+
+// Query starts a typed chain on T's table, e.g.
+// gom.Query[Person](db).Where("status=?", 1).All(ctx).
+func Query[T any](db *ConDB) *TypedQuery[T] {
+	var zero T
+	if t := reflect.TypeOf(zero); t == nil || t.Kind() != reflect.Struct {
+		return &TypedQuery[T]{db: db.clone(), err: fmt.Errorf("gom: Query needs a struct type, got %T", zero)}
+	}
+	return &TypedQuery[T]{db: db.Model(zero)}
+}
+
+// Table overrides the table inferred from T.
+func (q *TypedQuery[T]) Table(name string) *TypedQuery[T] {
+	q.db.Table(name)
+	return q
+}
+
+func (q *TypedQuery[T]) Where(query string, values ...interface{}) *TypedQuery[T] {
+	q.db.Where(query, values...)
+	return q
+}
+
+func (q *TypedQuery[T]) Or(query string, values ...interface{}) *TypedQuery[T] {
+	q.db.Or(query, values...)
+	return q
+}
+
+func (q *TypedQuery[T]) In(key string, values []interface{}) *TypedQuery[T] {
+	q.db.In(key, values)
+	return q
+}
+
+func (q *TypedQuery[T]) Maps(filters map[string]interface{}) *TypedQuery[T] {
+	q.db.Maps(filters)
+	return q
+}
+
+// Scopes applies fns to the underlying chain, see ConDB.Scopes.
+func (q *TypedQuery[T]) Scopes(fns ...Scope) *TypedQuery[T] {
+	q.db = q.db.Scopes(fns...)
+	return q
+}
+
+// Scope applies scopes registered for T's table, see ConDB.Scope.
+func (q *TypedQuery[T]) Scope(names ...string) *TypedQuery[T] {
+	q.db = q.db.Scope(names...)
+	return q
+}
+
+func (q *TypedQuery[T]) Field(field string) *TypedQuery[T] {
+	q.db.Field(field)
+	return q
+}
+
+func (q *TypedQuery[T]) GroupBy(field string) *TypedQuery[T] {
+	q.db.GroupBy(field)
+	return q
+}
+
+func (q *TypedQuery[T]) OrderBy(field string) *TypedQuery[T] {
+	q.db.OrderBy(field)
+	return q
+}
+
+func (q *TypedQuery[T]) Sort(key, sort string) *TypedQuery[T] {
+	q.db.Sort(key, sort)
+	return q
+}
+
+func (q *TypedQuery[T]) Page(cur, count int32) *TypedQuery[T] {
+	q.db.Page(cur, count)
+	return q
+}
+
+func (q *TypedQuery[T]) Limit(offset, size int32) *TypedQuery[T] {
+	q.db.Limit(offset, size)
+	return q
+}
+
+func (q *TypedQuery[T]) UsePrimary() *TypedQuery[T] {
+	q.db.UsePrimary()
+	return q
+}
+
+// Tx runs the query inside tx.
+func (q *TypedQuery[T]) Tx(tx *ConDB) *TypedQuery[T] {
+	q.db.tx = tx.tx
+	return q
+}
+
+// All returns every matching row.
+func (q *TypedQuery[T]) All(ctx context.Context) ([]T, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+	var out []T
+	err := q.db.WithContext(ctx).Find(&out)
+	return out, err
+}
+
+// First returns the first matching row or sql.ErrNoRows.
+func (q *TypedQuery[T]) First(ctx context.Context) (T, error) {
+	var out T
+	if q.err != nil {
+		return out, q.err
+	}
+	err := q.db.WithContext(ctx).Get(&out)
+	return out, err
+}
+
+// Count returns the number of matching rows.
+func (q *TypedQuery[T]) Count(ctx context.Context) (int64, error) {
+	if q.err != nil {
+		return 0, q.err
+	}
+	db := q.db.WithContext(ctx)
+	db.Err = nil
+	n := db.Count()
+	return n, db.Err
+}
+
+// Exists reports whether any row matches.
+func (q *TypedQuery[T]) Exists(ctx context.Context) (bool, error) {
+	if q.err != nil {
+		return false, q.err
+	}
+	return q.db.WithContext(ctx).IsExit()
+}
+
+// Paginate returns one page of rows along with the page metadata.
+func (q *TypedQuery[T]) Paginate(ctx context.Context, page, size int32) ([]T, Pagination, error) {
+	if q.err != nil {
+		return nil, Pagination{}, q.err
+	}
+	var out []T
+	p, err := q.db.WithContext(ctx).Paginate(page, size, &out)
+	return out, p, err
+}
+
+// Each streams matching rows into fn; see ConDB.Each.
+func (q *TypedQuery[T]) Each(ctx context.Context, fn func(T) error) error {
+	if q.err != nil {
+		return q.err
+	}
+	return q.db.WithContext(ctx).Each(fn)
+}
@@ -0,0 +1,55 @@
+package gom
+
+import (
+	"errors"
+	"reflect"
+)
+
+// ErrStaleObject is returned by Save when a struct with a gom:"version"
+// field no longer matches the stored version, i.e. someone else updated
+// the row after it was loaded.
+var ErrStaleObject = errors.New("gom: stale object, version mismatch")
+
+// versionField 查找带 gom:"version" 标签的列
+func versionField(t reflect.Type) (string, []int, bool) {
+	for col, idx := range getFieldMap(t) {
+		if _, ok := gomOption(idx.Tag, "version"); ok && idx.Tag.Get("db") != "" {
+			return col, idx.Index, true
+		}
+	}
+	return "", nil, false
+}
+
+// initVersion 插入前将为零的版本号置为 1
+func initVersion(rv reflect.Value) {
+	_, index, ok := versionField(rv.Type())
+	if !ok {
+		return
+	}
+	fv := rv.FieldByIndex(index)
+	if !fv.CanSet() || !fv.IsZero() {
+		return
+	}
+	setVersion(fv, 1)
+}
+
+// setVersion 写入整数类型的版本号
+func setVersion(fv reflect.Value, n int64) {
+	switch fv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		fv.SetUint(uint64(n))
+	}
+}
+
+// versionNumber 读取整数类型的版本号
+func versionNumber(fv reflect.Value) (int64, bool) {
+	switch fv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(fv.Uint()), true
+	}
+	return 0, false
+}
@@ -0,0 +1,66 @@
+package gom
+
+import (
+	"reflect"
+	"testing"
+)
+
+type versionDoc struct {
+	Id      int64  `db:"id"`
+	Title   string `db:"title"`
+	Version int    `db:"version" gom:"version"`
+}
+
+func TestSaveVersion(t *testing.T) {
+	table := getTable(versionDoc{})
+	tests := []struct {
+		name     string
+		affected int64
+		err      error
+		version  int
+	}{
+		{"current", 1, nil, 4},
+		{"stale", 0, ErrStaleObject, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &testDriver{affected: func(string) int64 { return tt.affected }}
+			db := &ConDB{Db: d.open(t)}
+			doc := &versionDoc{Id: 1, Title: "x", Version: 3}
+			if err := db.Save(doc); err != tt.err {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if doc.Version != tt.version {
+				t.Errorf("version = %d, want %d", doc.Version, tt.version)
+			}
+			want := []string{"UPDATE " + table + " SET title = ?, version = version + 1 WHERE id = ? AND version = ?"}
+			if s := d.statements(); !reflect.DeepEqual(s, want) {
+				t.Errorf("statements = %q, want %q", s, want)
+			}
+			if args := d.args[0]; !reflect.DeepEqual(args, []interface{}{"x", int64(1), int64(3)}) {
+				t.Errorf("args = %v", args)
+			}
+		})
+	}
+}
+
+func TestInsertVersion(t *testing.T) {
+	dry := (&ConDB{}).DryRun()
+	doc := &versionDoc{Title: "x"}
+	if err := dry.Insert(doc); err != nil {
+		t.Fatal(err)
+	}
+	if st := dry.Statements(); len(st) != 1 || !reflect.DeepEqual(st[0].Args, []interface{}{"x", 1}) {
+		t.Errorf("statements = %+v, want the version inserted as 1", st)
+	}
+}
+
+func TestSaveVersionNotInteger(t *testing.T) {
+	type doc struct {
+		Id      int64  `db:"id"`
+		Version string `db:"version" gom:"version"`
+	}
+	if err := (&ConDB{}).DryRun().Table("tb_doc").Save(&doc{Id: 1, Version: "a"}); err == nil {
+		t.Error("Save with a string version field succeeded")
+	}
+}